@@ -8,36 +8,47 @@ type Algorithm interface {
 }
 
 // Dijkstra implements the Algorithm interface.
-type Dijkstra struct{}
+type Dijkstra struct {
+	Movement Movement
+}
 
 func (d Dijkstra) FindPath(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
-	return DijkstraAlgorithm(grid, startNode, endNode)
+	return DijkstraAlgorithm(grid, startNode, endNode, d.Movement)
 }
 
 // Astar implements the Algorithm interface.
-type Astar struct{}
+type Astar struct {
+	Movement  Movement
+	Heuristic Heuristic
+}
 
 func (a Astar) FindPath(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
-	return AstarAlgorithm(grid, startNode, endNode)
+	return AstarAlgorithm(grid, startNode, endNode, a.Movement, a.Heuristic)
 }
 
 // BFS implements the Algorithm interface.
-type BFS struct{}
+type BFS struct {
+	Movement Movement
+}
 
 func (b BFS) FindPath(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
-	return BFSAlgorithm(grid, startNode, endNode)
+	return BFSAlgorithm(grid, startNode, endNode, b.Movement)
 }
 
 // DFS implements the Algorithm interface.
-type DFS struct{}
+type DFS struct {
+	Movement Movement
+}
 
 func (d DFS) FindPath(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
-	return DFSAlgorithm(grid, startNode, endNode)
+	return DFSAlgorithm(grid, startNode, endNode, d.Movement)
 }
 
 // WallFollower implements the Algorithm interface.
-type WallFollower struct{}
+type WallFollower struct {
+	Movement Movement
+}
 
 func (w WallFollower) FindPath(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
-	return WallFollowerAlgorithm(grid, startNode, endNode)
+	return WallFollowerAlgorithm(grid, startNode, endNode, w.Movement)
 }
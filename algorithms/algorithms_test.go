@@ -0,0 +1,388 @@
+package algorithms_test
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+	"pathfinding_algorithms_test_runner/verify"
+)
+
+// parseLayout builds a layout from rows of '#' (wall), '.' (open), 'S'
+// (start), 'E' (end) and '*' (start and end).
+func parseLayout(t testing.TB, rows ...string) *maze.Layout {
+	t.Helper()
+
+	layout := &maze.Layout{Width: len(rows[0]), Height: len(rows)}
+	for y, row := range rows {
+		walls := make([]bool, len(row))
+		for x, c := range row {
+			switch c {
+			case '#':
+				walls[x] = true
+			case 'S':
+				layout.Start = maze.Cell{X: uint16(x), Y: uint16(y)}
+			case 'E':
+				layout.End = maze.Cell{X: uint16(x), Y: uint16(y)}
+			case '*':
+				layout.Start = maze.Cell{X: uint16(x), Y: uint16(y)}
+				layout.End = layout.Start
+			case '.':
+			default:
+				t.Fatalf("unexpected %q in layout", c)
+			}
+		}
+		layout.Walls = append(layout.Walls, walls)
+	}
+	return layout
+}
+
+// shortestPath follows the PreviousNode chain back from endNode.
+func shortestPath(endNode *maze.Node) []*maze.Node {
+	var path []*maze.Node
+	for node := endNode; node != nil; node = node.PreviousNode {
+		path = append([]*maze.Node{node}, path...)
+	}
+	return path
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		rows      []string
+		reachable bool
+		cost      float64
+	}{
+		{
+			name: "unreachable goal",
+			rows: []string{
+				"#######",
+				"#S.#..#",
+				"#..#.E#",
+				"#######",
+			},
+			reachable: false,
+		},
+		{
+			name: "start is end",
+			rows: []string{
+				"###",
+				"#*#",
+				"###",
+			},
+			reachable: true,
+			cost:      0,
+		},
+		{
+			name: "horizontal corridor",
+			rows: []string{
+				"###########",
+				"#S.......E#",
+				"###########",
+			},
+			reachable: true,
+			cost:      8,
+		},
+		{
+			name: "vertical corridor",
+			rows: []string{
+				"###",
+				"#S#",
+				"#.#",
+				"#.#",
+				"#E#",
+				"###",
+			},
+			reachable: true,
+			cost:      3,
+		},
+		{
+			name: "open field",
+			rows: []string{
+				"#########",
+				"#S......#",
+				"#.......#",
+				"#.......#",
+				"#.......#",
+				"#......E#",
+				"#########",
+			},
+			reachable: true,
+			cost:      10,
+		},
+		{
+			name: "detour",
+			rows: []string{
+				"#########",
+				"#S..#..E#",
+				"#.#.#.#.#",
+				"#.......#",
+				"#########",
+			},
+			reachable: true,
+			cost:      10,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, name := range algorithms.Names() {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				layout := parseLayout(t, tt.rows...)
+				alg, err := algorithms.New(name, algorithms.Options{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				grid, startNode, endNode := layout.NewGrid(1)
+				alg.FindPath(grid, startNode, endNode)
+				path := shortestPath(endNode)
+
+				truth := verify.Solve(layout, algorithms.Movement{})
+				if truth.Reachable != tt.reachable || (tt.reachable && truth.Cost != tt.cost) {
+					t.Fatalf("ground truth = %+v, want reachable %t with cost %v", truth, tt.reachable, tt.cost)
+				}
+
+				result := verify.Check(layout, algorithms.Movement{}, truth, path)
+				if !result.Passed() {
+					t.Fatalf("invalid path: %s", result.Err)
+				}
+				if result.Reached != tt.reachable {
+					t.Fatalf("reached = %t, want %t", result.Reached, tt.reachable)
+				}
+
+				info, _ := algorithms.Describe(name)
+				if info.ClaimsOptimal(algorithms.Movement{}, false) && tt.reachable && result.Cost != tt.cost {
+					t.Errorf("path cost = %v, want %v", result.Cost, tt.cost)
+				}
+			})
+		}
+	}
+}
+
+func TestFindPathDiagonal(t *testing.T) {
+	layout := parseLayout(t,
+		"#######",
+		"#S....#",
+		"#.....#",
+		"#.....#",
+		"#....E#",
+		"#######",
+	)
+
+	tests := []struct {
+		name     string
+		movement algorithms.Movement
+		cost     float64
+	}{
+		{"four-connected", algorithms.Movement{}, 7},
+		{"eight-connected", algorithms.Movement{Connectivity: algorithms.EightConnected}, 1 + 3*math.Sqrt2},
+	}
+
+	for _, tt := range tests {
+		for _, name := range []string{"dijkstra", "astar-octile"} {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				alg, err := algorithms.New(name, algorithms.Options{Movement: tt.movement})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				grid, startNode, endNode := layout.NewGrid(1)
+				alg.FindPath(grid, startNode, endNode)
+
+				cost := algorithms.PathCost(shortestPath(endNode))
+				if math.Abs(cost-tt.cost) > 1e-6 {
+					t.Errorf("path cost = %v, want %v", cost, tt.cost)
+				}
+			})
+		}
+	}
+}
+
+func TestLargeWeightedGrid(t *testing.T) {
+	// float32 distances drifted by more than verify's tolerance at this size.
+	layout, err := maze.Generate(maze.Options{Rows: 601, Cols: 601, Seed: 1, Terrain: true, LoopDensity: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	movement := algorithms.Movement{Connectivity: algorithms.EightConnected}
+	truth := verify.Solve(layout, movement)
+
+	for _, name := range []string{"dijkstra", "astar-octile"} {
+		t.Run(name, func(t *testing.T) {
+			alg, _ := algorithms.New(name, algorithms.Options{Movement: movement})
+			grid, startNode, endNode := layout.NewGrid(1)
+			alg.FindPath(grid, startNode, endNode)
+
+			// G holds the float64 distance rounded to float32.
+			if !truth.Reachable || math.Abs(float64(endNode.G)-truth.Cost) > 1e-7*truth.Cost {
+				t.Errorf("distance of the end = %v, ground truth %v", endNode.G, truth.Cost)
+			}
+			cost := algorithms.PathCost(shortestPath(endNode))
+			if math.Abs(cost-truth.Cost) > 1e-6 {
+				t.Errorf("path cost = %v, ground truth %v", cost, truth.Cost)
+			}
+		})
+	}
+}
+
+func TestNoCornerCutting(t *testing.T) {
+	layout := parseLayout(t,
+		"#####",
+		"#S#.#",
+		"#..E#",
+		"#####",
+	)
+	movement := algorithms.Movement{Connectivity: algorithms.EightConnected, NoCornerCutting: true}
+
+	for _, name := range algorithms.Names() {
+		t.Run(name, func(t *testing.T) {
+			alg, err := algorithms.New(name, algorithms.Options{Movement: movement})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			grid, startNode, endNode := layout.NewGrid(1)
+			alg.FindPath(grid, startNode, endNode)
+
+			result := verify.Check(layout, movement, verify.Solve(layout, movement), shortestPath(endNode))
+			if !result.Passed() {
+				t.Fatalf("invalid path: %s", result.Err)
+			}
+		})
+	}
+}
+
+func TestHeuristicByName(t *testing.T) {
+	start := &maze.Node{X: 1, Y: 1}
+	end := &maze.Node{X: 4, Y: 5}
+
+	tests := []struct {
+		name string
+		want float32
+	}{
+		{"manhattan", 7},
+		{"euclidean", 5},
+		{"chebyshev", 4},
+		{"octile", 4 + 3*(math.Sqrt2-1)},
+		{"zero", 0},
+		{"weighted-manhattan:1.5", 10.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := algorithms.HeuristicByName(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Heuristics must be symmetric and must not wrap around on
+			// the unsigned coordinates.
+			if got := h(start, end); math.Abs(float64(got-tt.want)) > 1e-5 {
+				t.Errorf("h(start, end) = %v, want %v", got, tt.want)
+			}
+			if got := h(end, start); math.Abs(float64(got-tt.want)) > 1e-5 {
+				t.Errorf("h(end, start) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"unknown", "weighted-manhattan", "weighted-manhattan:x", "weighted-manhattan:-1"} {
+		if _, err := algorithms.HeuristicByName(name); err == nil {
+			t.Errorf("HeuristicByName(%q) succeeded, want error", name)
+		}
+	}
+}
+
+// randomLayout builds a width x height grid with a wall border, interior
+// walls of the given density and random open start and end cells.
+func randomLayout(r *rand.Rand, width, height int, density float64, terrain bool) *maze.Layout {
+	layout := &maze.Layout{Width: width, Height: height}
+	var open []maze.Cell
+	for y := 0; y < height; y++ {
+		walls := make([]bool, width)
+		costs := make([]uint8, width)
+		for x := 0; x < width; x++ {
+			border := x == 0 || y == 0 || x == width-1 || y == height-1
+			walls[x] = border || r.Float64() < density
+			if !walls[x] {
+				open = append(open, maze.Cell{X: uint16(x), Y: uint16(y)})
+			}
+			costs[x] = []uint8{maze.CostRoad, maze.CostMud, maze.CostWater}[r.Intn(3)]
+		}
+		layout.Walls = append(layout.Walls, walls)
+		if terrain {
+			layout.Costs = append(layout.Costs, costs)
+		}
+	}
+	if len(open) == 0 {
+		layout.Walls[1][1] = false
+		open = append(open, maze.Cell{X: 1, Y: 1})
+	}
+
+	layout.Start = open[r.Intn(len(open))]
+	layout.End = open[r.Intn(len(open))]
+	return layout
+}
+
+func FuzzOptimalAlgorithmsAgree(f *testing.F) {
+	f.Add(int64(1), uint8(10), uint8(10), uint8(30), false, false)
+	f.Add(int64(2), uint8(25), uint8(5), uint8(10), true, false)
+	f.Add(int64(3), uint8(16), uint8(16), uint8(40), true, true)
+	f.Add(int64(4), uint8(3), uint8(3), uint8(0), false, true)
+
+	f.Fuzz(func(t *testing.T, seed int64, width, height, density uint8, terrain, diagonal bool) {
+		w, h := 3+int(width)%40, 3+int(height)%40
+		r := rand.New(rand.NewSource(seed))
+		layout := randomLayout(r, w, h, float64(density%80)/100, terrain)
+
+		movement := algorithms.Movement{}
+		if diagonal {
+			movement.Connectivity = algorithms.EightConnected
+		}
+		truth := verify.Solve(layout, movement)
+
+		for _, name := range algorithms.Names() {
+			info, _ := algorithms.Describe(name)
+			if !info.ClaimsOptimal(movement, terrain) {
+				continue
+			}
+
+			alg, err := algorithms.New(name, algorithms.Options{Movement: movement})
+			if err != nil {
+				t.Fatal(err)
+			}
+			grid, startNode, endNode := layout.NewGrid(1)
+			alg.FindPath(grid, startNode, endNode)
+
+			result := verify.Check(layout, movement, truth, shortestPath(endNode))
+			if !result.Passed() {
+				t.Fatalf("%s: invalid path on\n%s\n%s", name, render(layout), result.Err)
+			}
+			if truth.Reachable && !result.Optimal {
+				t.Fatalf("%s: path cost %v, shortest %v on\n%s", name, result.Cost, truth.Cost, render(layout))
+			}
+		}
+	})
+}
+
+func render(layout *maze.Layout) string {
+	var b strings.Builder
+	for y, row := range layout.Walls {
+		for x, wall := range row {
+			switch {
+			case x == int(layout.Start.X) && y == int(layout.Start.Y):
+				b.WriteByte('S')
+			case x == int(layout.End.X) && y == int(layout.End.Y):
+				b.WriteByte('E')
+			case wall:
+				b.WriteByte('#')
+			default:
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
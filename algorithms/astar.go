@@ -1,73 +1,72 @@
 package algorithms
 
 import (
-	"container/heap"
-	"math"
-
 	"pathfinding_algorithms_test_runner/maze"
 )
 
-func heuristic(node, endNode *maze.Node) float32 {
-	//manhattanDistance := float32(math.Abs(float64(node.X-endNode.X)) + math.Abs(float64(node.Y-endNode.Y)))
-	//euclideanDistance := float32(math.Sqrt(float64(node.X-endNode.X)*float64(node.X-endNode.X) + float64(node.Y-endNode.Y)*float64(node.Y-endNode.Y)))
-	//chebyshevDistance := float32(math.Max(float64(node.X-endNode.X), float64(node.Y-endNode.Y)))
-	canberraDistance := float32(math.Abs(float64(node.X-endNode.X))/(float64(node.X)+float64(endNode.X)) + math.Abs(float64(node.Y-endNode.Y))/(float64(node.Y)+float64(endNode.Y)))
-
-	return canberraDistance
-}
-
-func AstarAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
-	openList := &PriorityQueue{useAstar: true}
-	heap.Init(openList)
+// AstarAlgorithm performs an A* search guided by h; the Manhattan distance
+// is used if h is nil. Like DijkstraAlgorithm it accumulates the g-scores in
+// float64, so that both agree on the cost of a shortest path; G, F and
+// Distance hold them rounded for display.
+func AstarAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node, movement Movement, h Heuristic) []maze.Node {
+	if h == nil {
+		h = Manhattan
+	}
 
-	closedSet := make(map[*maze.Node]bool)
-	inOpenSet := make(map[*maze.Node]bool)
+	rows, cols := len(grid), len(grid[0])
+	gScores := make([]float64, rows*cols)
+	reached := make([]bool, rows*cols)
+	closed := make([]bool, rows*cols)
+	index := func(node *maze.Node) int { return int(node.Y)*cols + int(node.X) }
 	visitedNodesInOrder := []maze.Node{}
 
 	startNode.Distance = 0
 	startNode.G = 0
-	startNode.F = heuristic(startNode, endNode)
-	heap.Push(openList, startNode)
-	inOpenSet[startNode] = true
+	startNode.F = h(startNode, endNode)
+	reached[index(startNode)] = true
+	openList := &nodeQueue{{node: startNode, priority: float64(startNode.F)}}
 
 	for openList.Len() > 0 {
-		currentNode := heap.Pop(openList).(*maze.Node)
-		delete(inOpenSet, currentNode)
+		current := openList.pop()
+		currentNode := current.node
+
+		// Nodes are queued again when a shorter path to them is found, so
+		// stale entries are skipped.
+		if closed[index(currentNode)] || current.distance > gScores[index(currentNode)] {
+			continue
+		}
 
 		if currentNode == endNode {
 			return visitedNodesInOrder
 		}
 
-		closedSet[currentNode] = true
+		closed[index(currentNode)] = true
 		visitedNodesInOrder = append(visitedNodesInOrder, *currentNode)
 
-		neighbors := getUnvisitedNeighbors(currentNode, grid)
+		// Nodes already in the open set are marked visited, but a shorter
+		// path to them may still be found, so all neighbours are considered.
+		neighbors := getNeighbors(currentNode, grid, movement)
 		for _, neighbor := range neighbors {
-			if closedSet[neighbor] || neighbor.IsWall {
+			i := index(neighbor)
+			if closed[i] || neighbor.IsWall {
 				continue
 			}
 
-			gScore := currentNode.G + 1
-			hScore := heuristic(neighbor, endNode)
-
-			if !inOpenSet[neighbor] {
-				neighbor.Distance = uint32(gScore)
-				neighbor.G = gScore
-				neighbor.F = gScore + hScore
-				neighbor.PreviousNode = currentNode
-				neighbor.IsVisited = true
-				heap.Push(openList, neighbor)
-				inOpenSet[neighbor] = true
-			} else if gScore < neighbor.G {
-				neighbor.Distance = uint32(gScore)
-				neighbor.G = gScore
-				neighbor.F = gScore + hScore
-				neighbor.PreviousNode = currentNode
-				heap.Fix(openList, openList.IndexOf(neighbor))
+			gScore := current.distance + stepCost(currentNode, neighbor)
+			if reached[i] && gScore >= gScores[i] {
+				continue
 			}
+			fScore := gScore + float64(h(neighbor, endNode))
+			reached[i] = true
+			gScores[i] = gScore
+			neighbor.Distance = uint32(gScore)
+			neighbor.G = float32(gScore)
+			neighbor.F = float32(fScore)
+			neighbor.PreviousNode = currentNode
+			neighbor.IsVisited = true
+			openList.push(queuedNode{node: neighbor, priority: fScore, distance: gScore})
 		}
 	}
 
 	return visitedNodesInOrder
 }
-
@@ -0,0 +1,66 @@
+package algorithms_test
+
+import (
+	"fmt"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// benchmarkSeed fixes the benchmark mazes so that results are comparable
+// across commits with benchstat.
+const benchmarkSeed = 1
+
+var benchmarkSizes = []int{25, 101, 501}
+
+// BenchmarkFindPath mirrors the runner's scenarios: every registered
+// algorithm on single and multiple path mazes of increasing size. Only
+// FindPath is timed; the fresh grid for every iteration is built with the
+// timer stopped.
+func BenchmarkFindPath(b *testing.B) {
+	layouts := make(map[string]*maze.Layout)
+	for _, size := range benchmarkSizes {
+		for _, singlePath := range []bool{true, false} {
+			layouts[fmt.Sprintf("%d/%t", size, singlePath)] = maze.GenerateMaze(size, size, singlePath, benchmarkSeed)
+		}
+	}
+
+	for _, name := range algorithms.Names() {
+		b.Run(name, func(b *testing.B) {
+			for _, size := range benchmarkSizes {
+				for _, singlePath := range []bool{true, false} {
+					key := fmt.Sprintf("%d/%t", size, singlePath)
+					b.Run(key, func(b *testing.B) {
+						benchmarkFindPath(b, name, layouts[key])
+					})
+				}
+			}
+		})
+	}
+}
+
+func benchmarkFindPath(b *testing.B, name string, layout *maze.Layout) {
+	alg, err := algorithms.New(name, algorithms.Options{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var visitedNodes, pathLength int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		grid, startNode, endNode := layout.NewGrid(1)
+		b.StartTimer()
+
+		visitedNodes = len(alg.FindPath(grid, startNode, endNode))
+
+		b.StopTimer()
+		pathLength = len(shortestPath(endNode))
+		b.StartTimer()
+	}
+
+	b.ReportMetric(float64(visitedNodes), "visited/op")
+	b.ReportMetric(float64(pathLength), "pathlen/op")
+}
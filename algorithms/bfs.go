@@ -7,7 +7,7 @@ import (
 )
 
 // BFS performs a breadth-first search on the grid
-func BFSAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
+func BFSAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node, movement Movement) []maze.Node {
 	visitedNodesInOrder := []maze.Node{}
 	queue := []*maze.Node{}
 	startNode.Distance = 0
@@ -30,7 +30,7 @@ func BFSAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node
 			return visitedNodesInOrder
 		}
 
-		unvisitedNeighbors := getUnvisitedNeighbors(currentNode, grid)
+		unvisitedNeighbors := getUnvisitedNeighbors(currentNode, grid, movement)
 		for _, neighbor := range unvisitedNeighbors {
 			neighbor.Distance = currentNode.Distance + 1
 			neighbor.PreviousNode = currentNode
@@ -7,7 +7,7 @@ import (
 )
 
 // DFSAlgorithm performs a depth-first search on the grid
-func DFSAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
+func DFSAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node, movement Movement) []maze.Node {
 	visitedNodesInOrder := []maze.Node{}
 	startNode.Distance = 0
 	stack := []*maze.Node{startNode}
@@ -29,7 +29,7 @@ func DFSAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node
 			return visitedNodesInOrder
 		}
 
-		unvisitedNeighbors := getUnvisitedNeighbors(currentNode, grid)
+		unvisitedNeighbors := getUnvisitedNeighbors(currentNode, grid, movement)
 		for _, neighbor := range unvisitedNeighbors {
 			neighbor.Distance = currentNode.Distance + 1
 			neighbor.PreviousNode = currentNode
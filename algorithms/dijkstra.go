@@ -1,24 +1,31 @@
 package algorithms
 
 import (
-	"container/heap"
-
 	"pathfinding_algorithms_test_runner/maze"
 )
 
-func DijkstraAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
+// DijkstraAlgorithm accumulates the tentative distances in float64, since
+// float32 drifts by more than the verification tolerance on large weighted
+// grids. G and Distance hold them rounded and truncated for display.
+func DijkstraAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node, movement Movement) []maze.Node {
 	rows, cols := len(grid), len(grid[0])
 	visitedNodes := make([]maze.Node, 0, rows*cols)
+	distances := make([]float64, rows*cols)
+	reached := make([]bool, rows*cols)
+	index := func(node *maze.Node) int { return int(node.Y)*cols + int(node.X) }
 
 	startNode.Distance = 0
-	unvisitedNodes := &PriorityQueue{useAstar: false}
-	heap.Init(unvisitedNodes)
-	heap.Push(unvisitedNodes, startNode)
+	startNode.G = 0
+	reached[index(startNode)] = true
+	unvisitedNodes := &nodeQueue{{node: startNode}}
 
 	for unvisitedNodes.Len() > 0 {
-		closestNode := heap.Pop(unvisitedNodes).(*maze.Node)
+		closest := unvisitedNodes.pop()
+		closestNode := closest.node
 
-		if closestNode.IsWall || closestNode.IsVisited {
+		// Nodes are queued again when a shorter distance is found, so
+		// stale entries are skipped.
+		if closestNode.IsWall || closestNode.IsVisited || closest.distance > distances[index(closestNode)] {
 			continue
 		}
 
@@ -30,27 +37,22 @@ func DijkstraAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze
 		closestNode.IsVisited = true
 		visitedNodes = append(visitedNodes, *closestNode)
 
-		updateUnvisitedNeighbors(closestNode, grid, unvisitedNodes)
-	}
-
-	return visitedNodes
-}
-
-func updateUnvisitedNeighbors(node *maze.Node, grid [][]maze.Node, unvisitedNodes *PriorityQueue) {
-	for _, neighbor := range getUnvisitedNeighbors(node, grid) {
-		if neighbor.IsWall || neighbor.IsVisited {
-			continue
-		}
-
-		newDistance := node.Distance + 1
-		if newDistance < neighbor.Distance {
-			neighbor.Distance = newDistance
-			neighbor.PreviousNode = node
-			if !contains(unvisitedNodes, neighbor) {
-				heap.Push(unvisitedNodes, neighbor)
-			} else {
-				unvisitedNodes.update(neighbor, newDistance)
+		for _, neighbor := range getUnvisitedNeighbors(closestNode, grid, movement) {
+			if neighbor.IsWall {
+				continue
+			}
+			i := index(neighbor)
+			newDistance := closest.distance + stepCost(closestNode, neighbor)
+			if !reached[i] || newDistance < distances[i] {
+				reached[i] = true
+				distances[i] = newDistance
+				neighbor.Distance = uint32(newDistance)
+				neighbor.G = float32(newDistance)
+				neighbor.PreviousNode = closestNode
+				unvisitedNodes.push(queuedNode{node: neighbor, priority: newDistance, distance: newDistance})
 			}
 		}
 	}
+
+	return visitedNodes
 }
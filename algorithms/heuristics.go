@@ -0,0 +1,147 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// Heuristic estimates the cost of reaching endNode from node.
+type Heuristic func(node, endNode *maze.Node) float32
+
+// DefaultHeuristic is the heuristic used by the plain "astar" entry.
+const DefaultHeuristic = "manhattan"
+
+var heuristics = map[string]Heuristic{
+	"manhattan": Manhattan,
+	"euclidean": Euclidean,
+	"chebyshev": Chebyshev,
+	"octile":    Octile,
+	"canberra":  Canberra,
+	"zero":      Zero,
+}
+
+// admissible lists, per heuristic, the movements under which it never
+// overestimates the remaining cost. Movement costs are at least 1 per step,
+// so a heuristic that is exact on an empty grid is admissible on any maze.
+var admissible = map[string]map[Connectivity]bool{
+	"manhattan": {FourConnected: true},
+	"euclidean": {FourConnected: true, EightConnected: true},
+	"chebyshev": {FourConnected: true, EightConnected: true},
+	"octile":    {FourConnected: true, EightConnected: true},
+	"canberra":  {FourConnected: true},
+	"zero":      {FourConnected: true, EightConnected: true},
+}
+
+// weightedManhattan is the prefix of the parameterised heuristic name
+// "weighted-manhattan:<factor>".
+const weightedManhattan = "weighted-manhattan"
+
+// HeuristicNames returns the names accepted by HeuristicByName, sorted.
+func HeuristicNames() []string {
+	names := make([]string, 0, len(heuristics)+1)
+	for name := range heuristics {
+		names = append(names, name)
+	}
+	names = append(names, weightedManhattan+":<factor>")
+	sort.Strings(names)
+	return names
+}
+
+// HeuristicByName returns the heuristic called name. Weighted Manhattan is
+// written as "weighted-manhattan:<factor>", e.g. "weighted-manhattan:1.5".
+func HeuristicByName(name string) (Heuristic, error) {
+	if h, exists := heuristics[name]; exists {
+		return h, nil
+	}
+
+	factorStr, found := strings.CutPrefix(name, weightedManhattan+":")
+	if !found {
+		return nil, fmt.Errorf("unknown heuristic %q", name)
+	}
+	factor, err := strconv.ParseFloat(factorStr, 32)
+	if err != nil || factor < 0 {
+		return nil, fmt.Errorf("invalid weighted-manhattan factor %q", factorStr)
+	}
+	return WeightedManhattan(float32(factor)), nil
+}
+
+// IsAdmissible reports whether the heuristic called name never overestimates
+// the remaining cost under the given connectivity.
+func IsAdmissible(name string, connectivity Connectivity) bool {
+	if connectivity == 0 {
+		connectivity = FourConnected
+	}
+	if factorStr, found := strings.CutPrefix(name, weightedManhattan+":"); found {
+		factor, err := strconv.ParseFloat(factorStr, 32)
+		return err == nil && factor <= 1 && connectivity == FourConnected
+	}
+	return admissible[name][connectivity]
+}
+
+// axisDistances returns the absolute horizontal and vertical distances
+// between two nodes.
+func axisDistances(node, endNode *maze.Node) (float64, float64) {
+	dx := math.Abs(float64(node.X) - float64(endNode.X))
+	dy := math.Abs(float64(node.Y) - float64(endNode.Y))
+	return dx, dy
+}
+
+// Manhattan is the exact distance on an empty four-connected grid.
+func Manhattan(node, endNode *maze.Node) float32 {
+	dx, dy := axisDistances(node, endNode)
+	return float32(dx + dy)
+}
+
+// Euclidean is the straight-line distance.
+func Euclidean(node, endNode *maze.Node) float32 {
+	dx, dy := axisDistances(node, endNode)
+	return float32(math.Sqrt(dx*dx + dy*dy))
+}
+
+// Chebyshev is the number of king moves on an empty grid.
+func Chebyshev(node, endNode *maze.Node) float32 {
+	dx, dy := axisDistances(node, endNode)
+	return float32(math.Max(dx, dy))
+}
+
+// Octile is the exact distance between two cells on an empty
+// eight-connected grid with unit orthogonal and sqrt(2) diagonal steps.
+func Octile(node, endNode *maze.Node) float32 {
+	dx, dy := axisDistances(node, endNode)
+	return float32(math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy))
+}
+
+// Canberra is the Canberra distance between the coordinates; it is tiny
+// compared to the real distance and guides the search only weakly.
+func Canberra(node, endNode *maze.Node) float32 {
+	dx, dy := axisDistances(node, endNode)
+	sumX := float64(node.X) + float64(endNode.X)
+	sumY := float64(node.Y) + float64(endNode.Y)
+
+	distance := 0.0
+	if sumX > 0 {
+		distance += dx / sumX
+	}
+	if sumY > 0 {
+		distance += dy / sumY
+	}
+	return float32(distance)
+}
+
+// Zero turns A* into Dijkstra's algorithm.
+func Zero(node, endNode *maze.Node) float32 {
+	return 0
+}
+
+// WeightedManhattan scales the Manhattan distance by factor. Factors above
+// 1 trade optimality for fewer expanded nodes.
+func WeightedManhattan(factor float32) Heuristic {
+	return func(node, endNode *maze.Node) float32 {
+		return factor * Manhattan(node, endNode)
+	}
+}
@@ -1,68 +1,65 @@
 package algorithms
 
 import (
-	"container/heap"
 	"pathfinding_algorithms_test_runner/maze"
 )
 
-type PriorityQueue struct {
-	nodes    []*maze.Node
-	useAstar bool
+// queuedNode is a node queued with the distance it was reached at and its
+// priority, which is the distance itself for Dijkstra and the distance plus
+// the heuristic for A*.
+type queuedNode struct {
+	node     *maze.Node
+	priority float64
+	distance float64
 }
 
-func (pq PriorityQueue) Len() int { return len(pq.nodes) }
+// nodeQueue is a min-heap of queued nodes by priority, ties broken by the
+// shorter distance. It does not use container/heap, whose interface{}
+// values would allocate on every push.
+type nodeQueue []queuedNode
 
-func (pq PriorityQueue) Less(i, j int) bool {
-	if pq.useAstar {
-		if pq.nodes[i].F == pq.nodes[j].F {
-			return pq.nodes[i].G < pq.nodes[j].G
-		}
-		return pq.nodes[i].F < pq.nodes[j].F
-	}
-	return pq.nodes[i].Distance < pq.nodes[j].Distance
-}
-
-func (pq PriorityQueue) Swap(i, j int) {
-	pq.nodes[i], pq.nodes[j] = pq.nodes[j], pq.nodes[i]
-}
-
-func (pq *PriorityQueue) Push(x interface{}) {
-	node := x.(*maze.Node)
-	pq.nodes = append(pq.nodes, node)
-}
+func (q nodeQueue) Len() int { return len(q) }
 
-func (pq *PriorityQueue) Pop() interface{} {
-	old := pq.nodes
-	n := len(old)
-	node := old[n-1]
-	pq.nodes = old[0 : n-1]
-	return node
+func (q nodeQueue) less(i, j int) bool {
+	if q[i].priority == q[j].priority {
+		return q[i].distance < q[j].distance
+	}
+	return q[i].priority < q[j].priority
 }
 
-func (pq *PriorityQueue) update(node *maze.Node, distance uint32) {
-	for i, n := range pq.nodes {
-		if n == node {
-			pq.nodes[i].Distance = distance
-			heap.Fix(pq, i)
+func (q *nodeQueue) push(entry queuedNode) {
+	*q = append(*q, entry)
+	h := *q
+	for i := len(h) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
 			break
 		}
+		h[parent], h[i] = h[i], h[parent]
+		i = parent
 	}
 }
 
-func (pq *PriorityQueue) IndexOf(node *maze.Node) int {
-	for i, n := range pq.nodes {
-		if n == node {
-			return i
+func (q *nodeQueue) pop() queuedNode {
+	h := *q
+	top := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+	for i := 0; ; {
+		smallest, left, right := i, 2*i+1, 2*i+2
+		if left < len(h) && h.less(left, smallest) {
+			smallest = left
 		}
-	}
-	return -1
-}
-
-func contains(pq *PriorityQueue, node *maze.Node) bool {
-	for _, n := range pq.nodes {
-		if n == node {
-			return true
+		if right < len(h) && h.less(right, smallest) {
+			smallest = right
+		}
+		if smallest == i {
+			break
 		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
 	}
-	return false
+	*q = h
+	return top
 }
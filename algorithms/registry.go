@@ -0,0 +1,162 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// MaxAlgorithms bounds the registry so that one plus the index of every
+// algorithm fits the grid id of maze.Node.
+const MaxAlgorithms = math.MaxUint16
+
+// Options configures an algorithm instance created by New.
+type Options struct {
+	Movement Movement
+}
+
+// Factory creates a fresh instance of a registered algorithm.
+type Factory func(opts Options) Algorithm
+
+// Info describes a registered algorithm.
+type Info struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	// Optimal reports whether the algorithm guarantees a shortest path.
+	Optimal bool `json:"optimal"`
+	// Weighted reports whether the algorithm honours per-cell movement costs.
+	Weighted bool `json:"weighted"`
+	// Heuristic names the heuristic of A* variants.
+	Heuristic string `json:"heuristic,omitempty"`
+}
+
+type registration struct {
+	info    Info
+	factory Factory
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]registration{}
+	registryOrder []string
+)
+
+func init() {
+	Register("dijkstra", func(opts Options) Algorithm { return Dijkstra{Movement: opts.Movement} }, Info{
+		DisplayName: "Dijkstra",
+		Optimal:     true,
+		Weighted:    true,
+	})
+	mustRegisterAstar("astar", DefaultHeuristic)
+	Register("bfs", func(opts Options) Algorithm { return BFS{Movement: opts.Movement} }, Info{
+		DisplayName: "Breadth-first search",
+		Optimal:     true,
+	})
+	Register("dfs", func(opts Options) Algorithm { return DFS{Movement: opts.Movement} }, Info{
+		DisplayName: "Depth-first search",
+	})
+	Register("wallFollower", func(opts Options) Algorithm { return WallFollower{Movement: opts.Movement} }, Info{
+		DisplayName: "Wall follower",
+	})
+	for _, heuristic := range []string{"euclidean", "chebyshev", "octile", "canberra", "zero", "weighted-manhattan:2"} {
+		mustRegisterAstar("astar-"+heuristic, heuristic)
+	}
+}
+
+func mustRegisterAstar(name, heuristic string) {
+	if err := registerAstar(name, heuristic); err != nil {
+		panic(err)
+	}
+}
+
+func registerAstar(name, heuristic string) error {
+	h, err := HeuristicByName(heuristic)
+	if err != nil {
+		return err
+	}
+	Register(name, func(opts Options) Algorithm { return Astar{Movement: opts.Movement, Heuristic: h} }, Info{
+		DisplayName: fmt.Sprintf("A* (%s)", heuristic),
+		Optimal:     IsAdmissible(heuristic, FourConnected),
+		Weighted:    true,
+		Heuristic:   heuristic,
+	})
+	return nil
+}
+
+// RegisterAstar registers an A* variant using the named heuristic as
+// "astar-<heuristic>" unless it exists already, and returns its name.
+func RegisterAstar(heuristic string) (string, error) {
+	name := "astar-" + heuristic
+	if _, exists := Describe(name); exists {
+		return name, nil
+	}
+	if len(Names()) >= MaxAlgorithms {
+		return name, fmt.Errorf("cannot register %q, the registry is full with %d algorithms", name, MaxAlgorithms)
+	}
+	return name, registerAstar(name, heuristic)
+}
+
+// ClaimsOptimal reports whether the algorithm guarantees a shortest path
+// under movement, on a grid with terrain costs if weighted is set.
+func (i Info) ClaimsOptimal(movement Movement, weighted bool) bool {
+	if !i.Optimal {
+		return false
+	}
+	if i.Heuristic != "" && !IsAdmissible(i.Heuristic, movement.Connectivity) {
+		return false
+	}
+	// Diagonal steps cost more than orthogonal ones, so they weight the
+	// grid just like terrain does.
+	return i.Weighted || (!weighted && !movement.Diagonal())
+}
+
+// Register makes an algorithm available under name. Algorithms are
+// enumerated in registration order. It panics if name is already taken or
+// MaxAlgorithms are registered.
+func Register(name string, factory Factory, info Info) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("algorithms: %q registered twice", name))
+	}
+	if len(registryOrder) >= MaxAlgorithms {
+		panic(fmt.Sprintf("algorithms: cannot register %q, the registry is full", name))
+	}
+	info.Name = name
+	if info.DisplayName == "" {
+		info.DisplayName = name
+	}
+	registry[name] = registration{info: info, factory: factory}
+	registryOrder = append(registryOrder, name)
+}
+
+// Names returns the names of all registered algorithms in registration order.
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	return append([]string(nil), registryOrder...)
+}
+
+// New returns a new instance of the algorithm registered under name,
+// configured with opts.
+func New(name string, opts Options) (Algorithm, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	r, exists := registry[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown algorithm %q", name)
+	}
+	return r.factory(opts), nil
+}
+
+// Describe returns the metadata of the algorithm registered under name.
+func Describe(name string) (Info, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	r, exists := registry[name]
+	return r.info, exists
+}
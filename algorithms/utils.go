@@ -1,45 +1,100 @@
 package algorithms
 
 import (
+	"math"
+
 	"pathfinding_algorithms_test_runner/maze"
 )
 
-func getUnvisitedNeighbors(node *maze.Node, grid [][]maze.Node) []*maze.Node {
-	neighbors := make([]*maze.Node, 0, 4) // Preallocate slice with capacity 4
-	row, col := node.Y, node.X
-	maxRow, maxCol := uint16(len(grid)-1), uint16(len(grid[0])-1)
+// Connectivity is the number of cells a node can move to in one step.
+type Connectivity uint8
 
-	// Check the top neighbor
-	if row > 0 {
-		neighbor := &grid[row-1][col]
-		if !neighbor.IsVisited {
-			neighbors = append(neighbors, neighbor)
-		}
+const (
+	FourConnected  Connectivity = 4
+	EightConnected Connectivity = 8
+)
+
+// Movement describes how an algorithm may move between cells. The zero
+// value is four-connected movement.
+type Movement struct {
+	Connectivity Connectivity
+	// NoCornerCutting forbids diagonal moves past the corner of a wall.
+	NoCornerCutting bool
+}
+
+// Diagonal reports whether diagonal moves are allowed.
+func (m Movement) Diagonal() bool {
+	return m.Connectivity == EightConnected
+}
+
+// directions lists the (row, col) offsets of the neighbours clockwise,
+// starting at the top. Even indices are the orthogonal directions.
+var directions = [8][2]int{
+	{-1, 0}, {-1, 1}, {0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1},
+}
+
+// neighborInDirection returns the cell reached from node by moving in
+// direction dir, or nil if the move leaves the grid or is not allowed by
+// movement.
+func neighborInDirection(node *maze.Node, grid [][]maze.Node, dir int, movement Movement) *maze.Node {
+	d := directions[dir]
+	row, col := int(node.Y)+d[0], int(node.X)+d[1]
+	if row < 0 || col < 0 || row >= len(grid) || col >= len(grid[0]) {
+		return nil
 	}
 
-	// Check the right neighbor
-	if col < maxCol {
-		neighbor := &grid[row][col+1]
-		if !neighbor.IsVisited {
-			neighbors = append(neighbors, neighbor)
+	if dir%2 == 1 {
+		if !movement.Diagonal() {
+			return nil
+		}
+		if movement.NoCornerCutting && (grid[row][node.X].IsWall || grid[node.Y][col].IsWall) {
+			return nil
 		}
 	}
 
-	// Check the bottom neighbor
-	if row < maxRow {
-		neighbor := &grid[row+1][col]
-		if !neighbor.IsVisited {
+	return &grid[row][col]
+}
+
+func getUnvisitedNeighbors(node *maze.Node, grid [][]maze.Node, movement Movement) []*maze.Node {
+	neighbors := make([]*maze.Node, 0, 8) // Preallocate slice with capacity 8
+
+	for dir := range directions {
+		neighbor := neighborInDirection(node, grid, dir, movement)
+		if neighbor != nil && !neighbor.IsVisited {
 			neighbors = append(neighbors, neighbor)
 		}
 	}
 
-	// Check the left neighbor
-	if col > 0 {
-		neighbor := &grid[row][col-1]
-		if !neighbor.IsVisited {
+	return neighbors
+}
+
+// getNeighbors is like getUnvisitedNeighbors but includes visited nodes.
+func getNeighbors(node *maze.Node, grid [][]maze.Node, movement Movement) []*maze.Node {
+	neighbors := make([]*maze.Node, 0, 8)
+
+	for dir := range directions {
+		if neighbor := neighborInDirection(node, grid, dir, movement); neighbor != nil {
 			neighbors = append(neighbors, neighbor)
 		}
 	}
 
 	return neighbors
 }
+
+// stepCost returns the cost of moving from one cell to an adjacent one:
+// the cost of the entered cell, scaled by sqrt(2) for diagonal moves.
+func stepCost(from, to *maze.Node) float64 {
+	if from.X != to.X && from.Y != to.Y {
+		return float64(to.Cost) * math.Sqrt2
+	}
+	return float64(to.Cost)
+}
+
+// PathCost sums the step costs along path.
+func PathCost(path []*maze.Node) float64 {
+	cost := 0.0
+	for i := 1; i < len(path); i++ {
+		cost += stepCost(path[i-1], path[i])
+	}
+	return cost
+}
@@ -2,16 +2,27 @@ package algorithms
 
 import "pathfinding_algorithms_test_runner/maze"
 
-// Directions
+// Directions, clockwise in steps of 45 degrees; they index directions.
 const (
-	Left = iota
-	Up
+	Up = iota
+	UpRight
 	Right
+	DownRight
 	Down
+	DownLeft
+	Left
+	UpLeft
+)
+
+// Turns relative to the current direction, in the order they are tried:
+// straight ahead, then the left-hand side, the right-hand side and back.
+var (
+	orthogonalTurns = []int{0, -2, 2, 4}
+	diagonalTurns   = []int{0, -1, 1, -2, 2, -3, 3, 4}
 )
 
 // WallFollowerAlgorithm performs the wall follower algorithm on the grid
-func WallFollowerAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []maze.Node {
+func WallFollowerAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node, movement Movement) []maze.Node {
 	visitedNodesInOrder := []maze.Node{}
 	startNode.Distance = 0
 	currentNode := startNode
@@ -24,7 +35,7 @@ func WallFollowerAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []
 		currentNode.NoOfVisits++
 		visitedNodesInOrder = append(visitedNodesInOrder, *currentNode)
 
-		neighbors := getPrioritizedNeighbors(currentNode, grid, currentDirection)
+		neighbors := getPrioritizedNeighbors(currentNode, grid, currentDirection, movement)
 		var nextNode *maze.Node
 
 		for _, neighbor := range neighbors {
@@ -45,7 +56,7 @@ func WallFollowerAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []
 			if previousNode != nil {
 				currentNode = previousNode
 				previousNode = currentNode.PreviousNode
-				currentDirection = (currentDirection + 2) % 4 // Reverse direction
+				currentDirection = (currentDirection + 4) % 8 // Reverse direction
 			} else {
 				break // No more backtracking possible
 			}
@@ -57,30 +68,17 @@ func WallFollowerAlgorithm(grid [][]maze.Node, startNode, endNode *maze.Node) []
 	return visitedNodesInOrder
 }
 
-// getPrioritizedNeighbors returns the neighbors of the node in the order of left, up, right, down relative to the current direction
-func getPrioritizedNeighbors(node *maze.Node, grid [][]maze.Node, direction int) []*maze.Node {
-	var neighbors []*maze.Node
-	row, col := node.Y, node.X
-	maxRow, maxCol := uint16(len(grid)-1), uint16(len(grid[0])-1)
-
-	// Define the order of directions to check based on the current direction
-	var directions [4][2]int
-	switch direction {
-	case Left:
-		directions = [4][2]int{{0, -1}, {1, 0}, {-1, 0}, {0, 1}} // Left, Down, Up, Right
-	case Up:
-		directions = [4][2]int{{-1, 0}, {0, -1}, {0, 1}, {1, 0}} // Up, Left, Right, Down
-	case Right:
-		directions = [4][2]int{{0, 1}, {-1, 0}, {1, 0}, {0, -1}} // Right, Up, Down, Left
-	case Down:
-		directions = [4][2]int{{1, 0}, {0, 1}, {0, -1}, {-1, 0}} // Down, Right, Left, Up
+// getPrioritizedNeighbors returns the neighbors of the node in the order of straight, left, right, back relative to the current direction
+func getPrioritizedNeighbors(node *maze.Node, grid [][]maze.Node, direction int, movement Movement) []*maze.Node {
+	turns := orthogonalTurns
+	if movement.Diagonal() {
+		turns = diagonalTurns
 	}
 
-	// Check neighbors in the prioritized order
-	for _, dir := range directions {
-		newRow, newCol := row+uint16(dir[0]), col+uint16(dir[1])
-		if newRow <= maxRow && newCol <= maxCol {
-			neighbors = append(neighbors, &grid[newRow][newCol])
+	neighbors := make([]*maze.Node, 0, len(turns))
+	for _, turn := range turns {
+		if neighbor := neighborInDirection(node, grid, (direction+turn+8)%8, movement); neighbor != nil {
+			neighbors = append(neighbors, neighbor)
 		}
 	}
 
@@ -89,14 +87,22 @@ func getPrioritizedNeighbors(node *maze.Node, grid [][]maze.Node, direction int)
 
 // getDirection determines the direction from currentNode to nextNode
 func getDirection(currentNode, nextNode *maze.Node) int {
-	if nextNode.Y == currentNode.Y {
-		if nextNode.X < currentNode.X {
-			return Left
+	dRow := sign(int(nextNode.Y) - int(currentNode.Y))
+	dCol := sign(int(nextNode.X) - int(currentNode.X))
+	for dir, d := range directions {
+		if d[0] == dRow && d[1] == dCol {
+			return dir
 		}
-		return Right
 	}
-	if nextNode.Y < currentNode.Y {
-		return Up
+	return Right
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
 	}
-	return Down
+	return 0
 }
@@ -0,0 +1,179 @@
+// Package chart renders simple line charts with error bars as SVG or PNG.
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// Series is one line of a chart. Err holds the half height of the error bar
+// at every point and may be nil.
+type Series struct {
+	Name string
+	X    []float64
+	Y    []float64
+	Err  []float64
+}
+
+// Chart is a line chart of one or more series over a shared x axis.
+type Chart struct {
+	Title  string
+	XLabel string
+	YLabel string
+	Series []Series
+}
+
+// Palette is the tab10 palette the Python plots used.
+var Palette = []color.RGBA{
+	{0x1f, 0x77, 0xb4, 0xff},
+	{0xff, 0x7f, 0x0e, 0xff},
+	{0x2c, 0xa0, 0x2c, 0xff},
+	{0xd6, 0x27, 0x28, 0xff},
+	{0x94, 0x67, 0xbd, 0xff},
+	{0x8c, 0x56, 0x4b, 0xff},
+	{0xe3, 0x77, 0xc2, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff},
+	{0xbc, 0xbd, 0x22, 0xff},
+	{0x17, 0xbe, 0xcf, 0xff},
+}
+
+var (
+	black = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	grey  = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+)
+
+// anchor is the horizontal alignment of text relative to its position.
+type anchor int
+
+const (
+	start anchor = iota
+	middle
+	end
+)
+
+// canvas is what a chart is drawn on, in pixels from the top left corner.
+type canvas interface {
+	line(x1, y1, x2, y2 float64, c color.RGBA, width float64)
+	circle(x, y, r float64, c color.RGBA)
+	// text draws s with its baseline at y.
+	text(x, y float64, s string, a anchor, c color.RGBA)
+}
+
+const (
+	marginLeft   = 70
+	marginRight  = 170
+	marginTop    = 50
+	marginBottom = 50
+	tickLength   = 5
+)
+
+// bounds returns the range of the data including the error bars. The y
+// range always includes zero, as in the original plots.
+func (c *Chart) bounds() (minX, maxX, minY, maxY float64) {
+	minX, maxX = math.Inf(1), math.Inf(-1)
+	minY, maxY = 0, math.Inf(-1)
+	for _, s := range c.Series {
+		for i := range s.X {
+			low, high := s.Y[i], s.Y[i]
+			if s.Err != nil {
+				low, high = low-s.Err[i], high+s.Err[i]
+			}
+			minX, maxX = math.Min(minX, s.X[i]), math.Max(maxX, s.X[i])
+			minY, maxY = math.Min(minY, low), math.Max(maxY, high)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return 0, 1, 0, 1
+	}
+	if maxX == minX {
+		minX, maxX = minX-1, maxX+1
+	}
+	if maxY == minY {
+		maxY = minY + 1
+	}
+	return minX, maxX, minY, maxY
+}
+
+// ticks returns about n round values covering [min, max], or just min
+// when the range is empty.
+func ticks(min, max float64, n int) []float64 {
+	if !(max > min) || n < 1 {
+		return []float64{min}
+	}
+	raw := (max - min) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, factor := range []float64{1, 2, 5, 10} {
+		step = factor * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	var values []float64
+	for v := math.Ceil(min/step) * step; v <= max+step*1e-9; v += step {
+		values = append(values, v)
+	}
+	return values
+}
+
+func formatTick(v float64) string {
+	if math.Abs(v) >= 1e6 {
+		return strconv.FormatFloat(v, 'g', 3, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// draw renders the chart on a canvas of the given size.
+func (c *Chart) draw(cv canvas, width, height int) {
+	minX, maxX, minY, maxY := c.bounds()
+	yTicks := ticks(minY, maxY, 8)
+	maxY = math.Max(maxY, yTicks[len(yTicks)-1])
+	left, right := float64(marginLeft), float64(width-marginRight)
+	top, bottom := float64(marginTop), float64(height-marginBottom)
+	px := func(x float64) float64 { return left + (x-minX)/(maxX-minX)*(right-left) }
+	py := func(y float64) float64 { return bottom - (y-minY)/(maxY-minY)*(bottom-top) }
+
+	cv.text(float64(width)/2, top/2, c.Title, middle, black)
+	cv.text(left, top-10, c.YLabel, middle, black)
+	cv.text((left+right)/2, float64(height)-10, c.XLabel, middle, black)
+
+	for _, y := range yTicks {
+		cv.line(left, py(y), right, py(y), grey, 1)
+		cv.line(left-tickLength, py(y), left, py(y), black, 1)
+		cv.text(left-tickLength-3, py(y)+4, formatTick(y), end, black)
+	}
+	for _, x := range ticks(minX, maxX, 8) {
+		cv.line(px(x), bottom, px(x), bottom+tickLength, black, 1)
+		cv.text(px(x), bottom+tickLength+14, formatTick(x), middle, black)
+	}
+	cv.line(left, top, left, bottom, black, 1)
+	cv.line(left, bottom, right, bottom, black, 1)
+
+	for i, s := range c.Series {
+		col := Palette[i%len(Palette)]
+		for j := range s.X {
+			x, y := px(s.X[j]), py(s.Y[j])
+			if j > 0 {
+				cv.line(px(s.X[j-1]), py(s.Y[j-1]), x, y, col, 2)
+			}
+			if s.Err != nil && s.Err[j] > 0 {
+				low, high := py(s.Y[j]-s.Err[j]), py(s.Y[j]+s.Err[j])
+				cv.line(x, low, x, high, col, 1)
+				cv.line(x-3, low, x+3, low, col, 1)
+				cv.line(x-3, high, x+3, high, col, 1)
+			}
+			cv.circle(x, y, 2.5, col)
+		}
+
+		legendY := top + 10 + float64(i)*18
+		cv.line(right+15, legendY-4, right+35, legendY-4, col, 2)
+		cv.text(right+40, legendY, s.Name, start, black)
+	}
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,74 @@
+package chart
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTicks(t *testing.T) {
+	tests := []struct {
+		min, max float64
+		n        int
+		want     []float64
+	}{
+		{0, 10, 5, []float64{0, 2, 4, 6, 8, 10}},
+		{0, 1, 4, []float64{0, 0.5, 1}},
+		{50, 202, 8, []float64{60, 80, 100, 120, 140, 160, 180, 200}},
+		{0, 0, 8, []float64{0}},
+		{5, 5, 8, []float64{5}},
+		{3, 1, 8, []float64{3}},
+		{0, 1, 0, []float64{0}},
+	}
+	for _, test := range tests {
+		got := ticks(test.min, test.max, test.n)
+		if len(got) != len(test.want) {
+			t.Errorf("ticks(%v, %v, %d) = %v, want %v", test.min, test.max, test.n, got, test.want)
+			continue
+		}
+		for i := range got {
+			if diff := got[i] - test.want[i]; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("ticks(%v, %v, %d) = %v, want %v", test.min, test.max, test.n, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		series []Series
+		want   [4]float64
+	}{
+		{"no series", nil, [4]float64{0, 1, 0, 1}},
+		{"empty series", []Series{{Name: "a"}}, [4]float64{0, 1, 0, 1}},
+		{"one point", []Series{{X: []float64{51}, Y: []float64{3}}}, [4]float64{50, 52, 0, 3}},
+		{"all zeros", []Series{{X: []float64{0, 0}, Y: []float64{0, 0}, Err: []float64{0, 0}}}, [4]float64{-1, 1, 0, 1}},
+		{"error bars", []Series{{X: []float64{1, 2}, Y: []float64{1, 4}, Err: []float64{2, 1}}}, [4]float64{1, 2, -1, 5}},
+	}
+	for _, test := range tests {
+		c := &Chart{Series: test.series}
+		minX, maxX, minY, maxY := c.bounds()
+		if got := [4]float64{minX, maxX, minY, maxY}; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRenderDegenerate(t *testing.T) {
+	charts := []*Chart{
+		{},
+		{Series: []Series{{Name: "one", X: []float64{51}, Y: []float64{0}}}},
+		{Series: []Series{{Name: "zeros", X: []float64{0, 0}, Y: []float64{0, 0}}}},
+	}
+	for _, c := range charts {
+		var b bytes.Buffer
+		if err := c.SVG(&b, 400, 300); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.PNG(&b, 400, 300); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+// line draws a line by stamping squares of the given width along it.
+func (p pngCanvas) line(x1, y1, x2, y2 float64, c color.RGBA, width float64) {
+	steps := int(math.Max(math.Abs(x2-x1), math.Abs(y2-y1))) + 1
+	half := int(width / 2)
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		x := int(math.Round(x1 + (x2-x1)*t))
+		y := int(math.Round(y1 + (y2-y1)*t))
+		for dy := -half; dy <= half-1+int(width)%2; dy++ {
+			for dx := -half; dx <= half-1+int(width)%2; dx++ {
+				p.img.SetRGBA(x+dx, y+dy, c)
+			}
+		}
+	}
+}
+
+func (p pngCanvas) circle(x, y, r float64, c color.RGBA) {
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy <= r*r {
+				p.img.SetRGBA(int(math.Round(x+dx)), int(math.Round(y+dy)), c)
+			}
+		}
+	}
+}
+
+func (p pngCanvas) text(x, y float64, s string, a anchor, c color.RGBA) {
+	drawer := &font.Drawer{Dst: p.img, Src: image.NewUniform(c), Face: basicfont.Face7x13}
+	width := drawer.MeasureString(s).Round()
+	switch a {
+	case middle:
+		x -= float64(width) / 2
+	case end:
+		x -= float64(width)
+	}
+	drawer.Dot = fixed.P(int(math.Round(x)), int(math.Round(y)))
+	drawer.DrawString(s)
+}
+
+// PNG writes the chart as a PNG image.
+func (c *Chart) PNG(w io.Writer, width, height int) error {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	c.draw(pngCanvas{img}, width, height)
+	return png.Encode(w, img)
+}
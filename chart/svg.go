@@ -0,0 +1,39 @@
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+)
+
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+func (s svgCanvas) line(x1, y1, x2, y2 float64, c color.RGBA, width float64) {
+	fmt.Fprintf(s.w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%g"/>`+"\n",
+		x1, y1, x2, y2, hex(c), width)
+}
+
+func (s svgCanvas) circle(x, y, r float64, c color.RGBA) {
+	fmt.Fprintf(s.w, `<circle cx="%.1f" cy="%.1f" r="%g" fill="%s"/>`+"\n", x, y, r, hex(c))
+}
+
+func (s svgCanvas) text(x, y float64, text string, a anchor, c color.RGBA) {
+	anchors := [...]string{start: "start", middle: "middle", end: "end"}
+	fmt.Fprintf(s.w, `<text x="%.1f" y="%.1f" text-anchor="%s" fill="%s">%s</text>`+"\n",
+		x, y, anchors[a], hex(c), html.EscapeString(text))
+}
+
+// SVG writes the chart as a standalone SVG image.
+func (c *Chart) SVG(w io.Writer, width, height int) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	c.draw(svgCanvas{bw}, width, height)
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
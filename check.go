@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// baselineSchemaVersion is incremented whenever the layout of baseline
+// changes.
+const baselineSchemaVersion = 2
+
+// checkSuite is the fixed, seeded set of mazes the check command runs.
+type checkSuite struct {
+	Seed       int64    `json:"seed"`
+	Sizes      []int    `json:"sizes"`
+	NumTests   int      `json:"numTests"`
+	Warmup     int      `json:"warmup"`
+	Generator  string   `json:"generator"`
+	Algorithms []string `json:"algorithms"`
+}
+
+// defaultCheckSuite is recorded by check -update.
+func defaultCheckSuite() checkSuite {
+	return checkSuite{
+		Seed:       1,
+		Sizes:      []int{51, 101, 201},
+		NumTests:   10,
+		Warmup:     1,
+		Generator:  maze.DefaultGenerator,
+		Algorithms: algorithms.Names(),
+	}
+}
+
+// baseline is the stored outcome of a checkSuite.
+type baseline struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Environment   environment      `json:"environment"`
+	Suite         checkSuite       `json:"suite"`
+	Results       []baselineResult `json:"results"`
+}
+
+// baselineResult is what is checked for one algorithm on one kind of
+// maze. Visited nodes are deterministic and kept per run; times are kept
+// per run for the significance test, and allocations as a median.
+type baselineResult struct {
+	Algorithm    string    `json:"algorithm"`
+	Size         int       `json:"size"`
+	SinglePath   bool      `json:"singlePath"`
+	Seeds        []int64   `json:"seeds"`
+	VisitedNodes []int     `json:"visitedNodes"`
+	TimesMs      []float64 `json:"timesMs"`
+	Allocs       float64   `json:"allocs"`
+}
+
+// runSuite runs suite and returns its results in the order of sizes, maze
+// types and algorithms.
+func runSuite(suite checkSuite) ([]baselineResult, error) {
+	cfg := runConfig{
+		seed:         suite.Seed,
+		algorithms:   suite.Algorithms,
+		generator:    suite.Generator,
+		mode:         isolatedMode,
+		warmup:       suite.Warmup,
+		madThreshold: stats.DefaultMADThreshold,
+		quiet:        true,
+	}
+
+	var results []baselineResult
+	for _, size := range suite.Sizes {
+		fmt.Printf("Running check suite for maze size %d\n", size)
+		metricsSPOn, metricsSPOff, err := collectMetrics(size, size, suite.NumTests, cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, singlePath := range []bool{true, false} {
+			metrics := metricsSPOff
+			if singlePath {
+				metrics = metricsSPOn
+			}
+			for _, algorithm := range suite.Algorithms {
+				metric := metrics[algorithm]
+				times := metric.values("time")
+				timesMs := make([]float64, len(times))
+				for i, t := range times {
+					timesMs[i] = t / 1e6
+				}
+				results = append(results, baselineResult{
+					Algorithm:    algorithm,
+					Size:         size,
+					SinglePath:   singlePath,
+					Seeds:        metric.Seeds,
+					VisitedNodes: metric.VisitedNodes,
+					TimesMs:      timesMs,
+					Allocs:       stats.Median(metric.values("allocs")),
+				})
+			}
+		}
+	}
+	return results, nil
+}
+
+// runCheck implements the check command, which runs the check suite and
+// compares it to a stored baseline, or records a new baseline with -update.
+// Visited nodes and allocations are always checked. Time is only checked
+// with -check-time, since it depends on the load of the machine.
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	baselineFile := flags.String("baseline", "data/baseline.json", "Baseline to compare against or to write with -update")
+	update := flags.Bool("update", false, "Record a new baseline instead of checking against it")
+	checkTimeFlag := flags.Bool("check-time", false, "Also check the time, on the machine the baseline was recorded on")
+	alpha := flags.Float64("alpha", 0.01, "Significance level below which a time increase is a regression")
+	timeTolerance := flags.Float64("time-tolerance", 0.25, "Allowed relative increase of the median time")
+	timeFloor := flags.Float64("time-floor", 0.05, "Increases of the median time below this many ms are never regressions")
+	allocsTolerance := flags.Float64("allocs-tolerance", 0.10, "Allowed relative increase of the median allocations")
+	flags.Parse(args)
+
+	if *update {
+		suite := defaultCheckSuite()
+		results, err := runSuite(suite)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(baseline{
+			SchemaVersion: baselineSchemaVersion,
+			Environment:   captureEnvironment(time.Now()),
+			Suite:         suite,
+			Results:       results,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Writing baseline to %s\n", *baselineFile)
+		return os.WriteFile(*baselineFile, data, 0o644)
+	}
+
+	data, err := os.ReadFile(*baselineFile)
+	if err != nil {
+		return err
+	}
+	var stored baseline
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("%s: %w", *baselineFile, err)
+	}
+	if stored.SchemaVersion != baselineSchemaVersion {
+		return fmt.Errorf("%s: unsupported schema version %d, want %d", *baselineFile, stored.SchemaVersion, baselineSchemaVersion)
+	}
+	for _, algorithm := range stored.Suite.Algorithms {
+		if _, exists := algorithms.Describe(algorithm); !exists {
+			return fmt.Errorf("baseline algorithm %q is not registered", algorithm)
+		}
+	}
+
+	results, err := runSuite(stored.Suite)
+	if err != nil {
+		return err
+	}
+	if len(results) != len(stored.Results) {
+		return fmt.Errorf("%s has %d results, the suite produced %d", *baselineFile, len(stored.Results), len(results))
+	}
+
+	// Times are only comparable on the machine the baseline was recorded on.
+	current := captureEnvironment(time.Now())
+	checkTime := *checkTimeFlag
+	if checkTime && (current.CPUModel != stored.Environment.CPUModel || current.NumCPU != stored.Environment.NumCPU) {
+		checkTime = false
+		fmt.Printf("Baseline was recorded on %q with %d CPUs, not checking time\n",
+			stored.Environment.CPUModel, stored.Environment.NumCPU)
+	}
+
+	regressions := 0
+	for i, want := range stored.Results {
+		got := results[i]
+		name := fmt.Sprintf("%s %dx%d %s", want.Algorithm, want.Size, want.Size, mazeType(want.SinglePath))
+
+		for run := range want.VisitedNodes {
+			if got.VisitedNodes[run] != want.VisitedNodes[run] {
+				fmt.Printf("FAIL %s: run %d (seed %d) visited %d nodes, baseline %d\n",
+					name, run, want.Seeds[run], got.VisitedNodes[run], want.VisitedNodes[run])
+				regressions++
+			}
+		}
+		if checkTime && timeRegressed(got.TimesMs, want.TimesMs, *timeTolerance, *timeFloor, *alpha) {
+			gotMs, wantMs := stats.Median(got.TimesMs), stats.Median(want.TimesMs)
+			fmt.Printf("FAIL %s: median time %.3f ms, baseline %.3f ms (%+.1f%%, tolerance %.0f%%, p=%.3f)\n",
+				name, gotMs, wantMs, change(gotMs, wantMs), *timeTolerance*100, stats.MannWhitneyU(want.TimesMs, got.TimesMs))
+			regressions++
+		}
+		if exceeds(got.Allocs, want.Allocs, *allocsTolerance) {
+			fmt.Printf("FAIL %s: median allocations %.0f, baseline %.0f (%+.1f%%, tolerance %.0f%%)\n",
+				name, got.Allocs, want.Allocs, change(got.Allocs, want.Allocs), *allocsTolerance*100)
+			regressions++
+		}
+	}
+
+	if regressions > 0 {
+		return fmt.Errorf("%d regressions against %s", regressions, *baselineFile)
+	}
+	fmt.Printf("OK: %d results match %s\n", len(stored.Results), *baselineFile)
+	return nil
+}
+
+func mazeType(singlePath bool) string {
+	if singlePath {
+		return "single path"
+	}
+	return "multiple paths"
+}
+
+// timeRegressed reports whether the times got are slower than want: the
+// median must rise by more than tolerance and by more than floor ms, and
+// the rise must be significant at alpha, so that a single slow run or a
+// tiny but consistent difference does not fail the check.
+func timeRegressed(got, want []float64, tolerance, floor, alpha float64) bool {
+	gotMs, wantMs := stats.Median(got), stats.Median(want)
+	if !exceeds(gotMs, wantMs, tolerance) || gotMs-wantMs <= floor {
+		return false
+	}
+	return stats.MannWhitneyU(want, got) < alpha
+}
+
+// exceeds reports whether got is more than tolerance above want.
+func exceeds(got, want, tolerance float64) bool {
+	return got > want*(1+tolerance)
+}
+
+// change is the relative change from want to got in percent.
+func change(got, want float64) float64 {
+	if want == 0 {
+		return 0
+	}
+	return (got - want) / want * 100
+}
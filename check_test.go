@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestTimeRegressed(t *testing.T) {
+	baseline := []float64{1.0, 1.1, 0.9, 1.0, 1.05, 0.95, 1.0, 1.1, 0.9, 1.0}
+	tests := []struct {
+		name string
+		got  []float64
+		want bool
+	}{
+		{"same", []float64{1.0, 1.05, 0.95, 1.1, 0.9, 1.0, 1.0, 1.1, 0.9, 1.0}, false},
+		{"consistently slower", []float64{2.0, 2.1, 1.9, 2.0, 2.05, 1.95, 2.0, 2.1, 1.9, 2.0}, true},
+		{"slower within tolerance", []float64{1.15, 1.2, 1.1, 1.15, 1.2, 1.1, 1.15, 1.2, 1.1, 1.15}, false},
+		{"one slow run", []float64{1.0, 1.05, 0.95, 1.1, 0.9, 1.0, 1.0, 1.1, 0.9, 9.0}, false},
+		{"slower but overlapping", []float64{0.9, 2.0, 1.0, 2.1, 0.95, 1.9, 1.05, 2.0, 1.0, 1.95}, false},
+	}
+	for _, test := range tests {
+		if got := timeRegressed(test.got, baseline, 0.25, 0.05, 0.01); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+	if timeRegressed([]float64{0.02, 0.021, 0.02}, []float64{0.01, 0.011, 0.01}, 0.25, 0.05, 0.01) {
+		t.Error("an increase below the floor is a regression")
+	}
+}
@@ -0,0 +1,561 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// Comparison output formats.
+const (
+	tableFormat    = "table"
+	markdownFormat = "markdown"
+)
+
+// resultFilePattern matches the files the runner writes, e.g.
+// averages1001x1001x10xAMDEpyc7763_4_16gb.csv or runs25x25x10.jsonl.
+var resultFilePattern = regexp.MustCompile(`^(averages|runs)(\d+)x(\d+)x(\d+)(?:x(.+))?\.(csv|json|jsonl)$`)
+
+// resultKey identifies the results of one algorithm on one kind of maze.
+type resultKey struct {
+	rows       int
+	cols       int
+	algorithm  string
+	singlePath bool
+}
+
+// resultEntry is what a result directory knows about one resultKey. Older
+// directories only have the means of the averages CSV; JSON results add
+// summaries and per-run exports the individual samples.
+type resultEntry struct {
+	means     map[string]float64
+	summaries map[string]stats.Summary
+	samples   map[string][]float64
+}
+
+// resultSet is the content of one result directory.
+type resultSet struct {
+	name    string
+	entries map[resultKey]*resultEntry
+}
+
+func (s *resultSet) entry(key resultKey) *resultEntry {
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &resultEntry{
+			means:     make(map[string]float64),
+			summaries: make(map[string]stats.Summary),
+			samples:   make(map[string][]float64),
+		}
+		s.entries[key] = entry
+	}
+	return entry
+}
+
+// normalizeSize maps an even maze size to the odd size the runner actually
+// generates, so that old results named after the requested size line up
+// with newer ones.
+func normalizeSize(size int) int {
+	if size%2 == 0 {
+		return size + 1
+	}
+	return size
+}
+
+// loadResultSet reads every result file in dir. The files of one run share
+// the part of their name after the size; files of different runs of the
+// same size, such as another number of tests or marker, would be blended
+// into one entry and are rejected.
+func loadResultSet(dir string) (*resultSet, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &resultSet{name: filepath.Base(filepath.Clean(dir)), entries: make(map[resultKey]*resultEntry)}
+	// JSON documents are read after the CSVs so that their exact means
+	// replace the rounded ones.
+	sort.Slice(files, func(i, j int) bool {
+		return filepath.Ext(files[i].Name()) < filepath.Ext(files[j].Name())
+	})
+	// runs holds, per size, the run the first file of that size belongs to.
+	type run struct{ id, file string }
+	runs := make(map[sweepSize]run)
+	for _, file := range files {
+		match := resultFilePattern.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+		rows, _ := strconv.Atoi(match[2])
+		cols, _ := strconv.Atoi(match[3])
+		rows, cols = normalizeSize(rows), normalizeSize(cols)
+		path := filepath.Join(dir, file.Name())
+
+		size := sweepSize{rows, cols}
+		id := match[2] + "x" + match[3] + "x" + match[4] + "x" + match[5]
+		if first, exists := runs[size]; !exists {
+			runs[size] = run{id, file.Name()}
+		} else if first.id != id {
+			return nil, fmt.Errorf("%s: %s and %s are different runs of size %s, keep one per directory",
+				dir, first.file, file.Name(), size)
+		}
+
+		switch match[1] + "." + match[6] {
+		case "averages.csv":
+			err = loadAveragesCsv(set, path, rows, cols)
+		case "averages.json":
+			err = loadAveragesJson(set, path)
+		case "runs.csv":
+			err = loadRunsCsv(set, path)
+		case "runs.jsonl":
+			err = loadRunsJsonl(set, path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if len(set.entries) == 0 {
+		return nil, fmt.Errorf("%s: no result files", dir)
+	}
+	return set, nil
+}
+
+func readCsv(path string) ([]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, column := range records[0] {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// csvMetrics parses the summarised metric columns of row, skipping missing
+// and N/A values.
+func csvMetrics(row map[string]string) map[string]float64 {
+	values := make(map[string]float64)
+	for _, m := range summarizedMetrics {
+		if value, err := strconv.ParseFloat(row[m.header()], 64); err == nil {
+			values[m.key] = value
+		}
+	}
+	return values
+}
+
+func loadAveragesCsv(set *resultSet, path string, rows, cols int) error {
+	records, err := readCsv(path)
+	if err != nil {
+		return err
+	}
+	for _, row := range records {
+		key := resultKey{rows, cols, row["Algorithm"], row["SinglePath"] == "true"}
+		for metric, value := range csvMetrics(row) {
+			set.entry(key).means[metric] = value
+		}
+	}
+	return nil
+}
+
+func loadAveragesJson(set *resultSet, path string) error {
+	document, err := readResultDocument(path)
+	if err != nil {
+		return err
+	}
+	rows, cols := normalizeSize(document.Config.Rows), normalizeSize(document.Config.Cols)
+	for _, result := range document.Results {
+		entry := set.entry(resultKey{rows, cols, result.Algorithm, result.SinglePath})
+		for metric, summary := range result.Metrics {
+			if summary.N == 0 {
+				continue
+			}
+			entry.means[metric] = summary.Mean
+			entry.summaries[metric] = summary
+		}
+	}
+	return nil
+}
+
+func loadRunsCsv(set *resultSet, path string) error {
+	records, err := readCsv(path)
+	if err != nil {
+		return err
+	}
+	for _, row := range records {
+		rows, _ := strconv.Atoi(row["Rows"])
+		cols, _ := strconv.Atoi(row["Cols"])
+		entry := set.entry(resultKey{normalizeSize(rows), normalizeSize(cols), row["Algorithm"], row["SinglePath"] == "true"})
+		for metric, value := range csvMetrics(row) {
+			entry.samples[metric] = append(entry.samples[metric], value)
+		}
+	}
+	return nil
+}
+
+func loadRunsJsonl(set *resultSet, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r runRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return err
+		}
+		entry := set.entry(resultKey{normalizeSize(r.Rows), normalizeSize(r.Cols), r.Algorithm, r.SinglePath})
+		for metric, value := range r.values() {
+			entry.samples[metric] = append(entry.samples[metric], value)
+		}
+	}
+	return scanner.Err()
+}
+
+// values returns the summarised metrics of the run in the units of their
+// summarizedMetric.
+func (r runRecord) values() map[string]float64 {
+	values := map[string]float64{
+		"visitedNodes":      float64(r.VisitedNodes),
+		"visitedPercentage": r.VisitedPercentage,
+		"pathLength":        float64(r.PathLength),
+		"pathCost":          r.PathCost,
+		"deadEnds":          float64(r.DeadEnds),
+		"loops":             float64(r.Loops),
+		"solutionFraction":  r.SolutionFraction,
+		"riverFactor":       r.RiverFactor,
+	}
+	if r.TimeMs != nil {
+		values["time"] = *r.TimeMs
+		values["memoryUsed"] = *r.MemoryUsedMB
+		values["allocs"] = float64(*r.Allocs)
+	}
+	if r.WallTimeMs != nil {
+		values["wallTime"] = *r.WallTimeMs
+		values["peakRSS"] = *r.PeakRSSMB
+		values["pageFaults"] = float64(*r.PageFaults)
+	}
+	return values
+}
+
+// comparison is one result set's value for a row, relative to the first
+// result set.
+type comparison struct {
+	present bool
+	mean    float64
+	// spread is the half width of the confidence interval relative to the
+	// mean, NaN if unknown.
+	spread float64
+	// delta is the relative change of the mean, speedup the ratio of the
+	// baseline mean to this one.
+	delta   float64
+	speedup float64
+	// p is the p-value of the significance test, NaN if there was not
+	// enough data for one.
+	p      float64
+	n1, n2 int
+}
+
+func (c comparison) significant(alpha float64) bool {
+	return !math.IsNaN(c.p) && c.p < alpha
+}
+
+// compare compares the metric of entry against base.
+func compare(metric string, base, entry *resultEntry) comparison {
+	c := comparison{spread: math.NaN(), p: math.NaN()}
+	if entry == nil {
+		return c
+	}
+	mean, exists := entry.means[metric]
+	if samples := entry.samples[metric]; len(samples) > 0 {
+		mean, exists = stats.Mean(samples), true
+	}
+	if !exists {
+		return c
+	}
+	c.present, c.mean = true, mean
+
+	if summary, exists := entry.summaries[metric]; exists && mean != 0 {
+		c.spread = (summary.CIHigh - summary.CILow) / 2 / math.Abs(mean)
+	}
+	if base == nil || base == entry {
+		return c
+	}
+
+	baseComparison := compare(metric, base, base)
+	if !baseComparison.present {
+		return c
+	}
+	if baseComparison.mean != 0 {
+		c.delta = (mean - baseComparison.mean) / baseComparison.mean
+	}
+	if mean != 0 {
+		c.speedup = baseComparison.mean / mean
+	}
+
+	baseSamples, samples := base.samples[metric], entry.samples[metric]
+	baseSummary, baseHasSummary := base.summaries[metric]
+	summary, hasSummary := entry.summaries[metric]
+	switch {
+	case len(baseSamples) > 1 && len(samples) > 1:
+		c.p = stats.MannWhitneyU(baseSamples, samples)
+		c.n1, c.n2 = len(baseSamples), len(samples)
+	case baseHasSummary && hasSummary:
+		c.p = stats.WelchTTest(baseSummary, summary)
+		c.n1, c.n2 = baseSummary.N, summary.N
+	}
+	return c
+}
+
+// runCompare implements the compare command, which aligns the results of
+// two or more directories and reports how each differs from the first.
+func runCompare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	metric := flags.String("metric", "time", "Metric to compare: "+strings.Join(summarizedMetricKeys(), ", "))
+	format := flags.String("format", tableFormat, "Output format: "+tableFormat+", "+markdownFormat+" or "+csvFormat)
+	alpha := flags.Float64("alpha", 0.05, "Significance level below which a difference is reported")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: compare [flags] <baseline dir> <dir> [dir ...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("compare needs at least two result directories")
+	}
+	if !isSummarizedMetric(*metric) {
+		return fmt.Errorf("unknown metric %q, available: %s", *metric, strings.Join(summarizedMetricKeys(), ", "))
+	}
+
+	var sets []*resultSet
+	for _, dir := range flags.Args() {
+		set, err := loadResultSet(dir)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+	}
+
+	switch *format {
+	case tableFormat, markdownFormat:
+		return writeComparisonTable(os.Stdout, *format, *metric, *alpha, sets)
+	case csvFormat:
+		return writeComparisonCsv(os.Stdout, *metric, sets)
+	}
+	return fmt.Errorf("format must be %s, %s or %s", tableFormat, markdownFormat, csvFormat)
+}
+
+func summarizedMetricKeys() []string {
+	var keys []string
+	for _, m := range summarizedMetrics {
+		keys = append(keys, m.key)
+	}
+	return keys
+}
+
+func isSummarizedMetric(key string) bool {
+	for _, m := range summarizedMetrics {
+		if m.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// comparisonKeys returns the keys of the baseline that have the metric,
+// ordered by maze size, maze type and algorithm.
+func comparisonKeys(metric string, base *resultSet) []resultKey {
+	var keys []resultKey
+	for key, entry := range base.entries {
+		if _, exists := entry.means[metric]; exists || len(entry.samples[metric]) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.rows != b.rows {
+			return a.rows < b.rows
+		}
+		if a.cols != b.cols {
+			return a.cols < b.cols
+		}
+		if a.singlePath != b.singlePath {
+			return a.singlePath
+		}
+		return a.algorithm < b.algorithm
+	})
+	return keys
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func formatMean(c comparison) string {
+	if !c.present {
+		return "-"
+	}
+	if math.IsNaN(c.spread) {
+		return formatValue(c.mean)
+	}
+	return fmt.Sprintf("%s ±%.0f%%", formatValue(c.mean), c.spread*100)
+}
+
+// formatDelta formats a change like benchstat: the relative change if it is
+// significant, ~ if it is not, and the plain change if it was not tested.
+func formatDelta(c comparison, alpha float64) string {
+	if !c.present || c.speedup == 0 {
+		return "-"
+	}
+	delta := fmt.Sprintf("%+.2f%%", c.delta*100)
+	switch {
+	case math.IsNaN(c.p):
+		return delta + " (?)"
+	case !c.significant(alpha):
+		return fmt.Sprintf("~ (p=%.3f n=%d+%d)", c.p, c.n1, c.n2)
+	}
+	return fmt.Sprintf("%s (p=%.3f n=%d+%d)", delta, c.p, c.n1, c.n2)
+}
+
+func writeComparisonTable(w io.Writer, format, metric string, alpha float64, sets []*resultSet) error {
+	header := []string{"Size", "SinglePath", "Algorithm", sets[0].name}
+	for _, set := range sets[1:] {
+		header = append(header, set.name, "Delta", "Speedup")
+	}
+
+	var rows [][]string
+	speedups := make([][]float64, len(sets))
+	for _, key := range comparisonKeys(metric, sets[0]) {
+		base := sets[0].entries[key]
+		row := []string{
+			fmt.Sprintf("%dx%d", key.rows, key.cols),
+			strconv.FormatBool(key.singlePath),
+			key.algorithm,
+			formatMean(compare(metric, nil, base)),
+		}
+		for i, set := range sets[1:] {
+			c := compare(metric, base, set.entries[key])
+			speedup := "-"
+			if c.present && c.speedup > 0 {
+				speedup = fmt.Sprintf("%.2fx", c.speedup)
+				speedups[i+1] = append(speedups[i+1], c.speedup)
+			}
+			row = append(row, formatMean(c), formatDelta(c, alpha), speedup)
+		}
+		rows = append(rows, row)
+	}
+
+	geomean := []string{"geomean", "", "", ""}
+	for _, s := range speedups[1:] {
+		if len(s) == 0 {
+			geomean = append(geomean, "", "-", "-")
+			continue
+		}
+		logSum := 0.0
+		for _, speedup := range s {
+			logSum += math.Log(speedup)
+		}
+		mean := math.Exp(logSum / float64(len(s)))
+		geomean = append(geomean, "", fmt.Sprintf("%+.2f%%", (1/mean-1)*100), fmt.Sprintf("%.2fx", mean))
+	}
+	rows = append(rows, geomean)
+
+	unit := ""
+	for _, m := range summarizedMetrics {
+		if m.key == metric && m.unit != "" {
+			unit = " [" + m.unit + "]"
+		}
+	}
+	fmt.Fprintf(w, "%s%s, significance level %.2f, ? marks changes without enough data for a test\n\n", metric, unit, alpha)
+
+	if format == markdownFormat {
+		fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(header)))
+		for _, row := range rows {
+			fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeComparisonCsv writes the comparison with one numeric value per
+// column for further processing.
+func writeComparisonCsv(w io.Writer, metric string, sets []*resultSet) error {
+	writer := csv.NewWriter(w)
+	header := []string{"Rows", "Cols", "SinglePath", "Algorithm", "Metric"}
+	for i, set := range sets {
+		header = append(header, set.name+" Mean", set.name+" CI95 [%]")
+		if i > 0 {
+			header = append(header, set.name+" Delta [%]", set.name+" Speedup", set.name+" P")
+		}
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	number := func(value float64, present bool) string {
+		if !present || math.IsNaN(value) {
+			return ""
+		}
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	for _, key := range comparisonKeys(metric, sets[0]) {
+		base := sets[0].entries[key]
+		row := []string{strconv.Itoa(key.rows), strconv.Itoa(key.cols), strconv.FormatBool(key.singlePath), key.algorithm, metric}
+		for i, set := range sets {
+			c := compare(metric, base, set.entries[key])
+			row = append(row, number(c.mean, c.present), number(c.spread*100, c.present))
+			if i > 0 {
+				row = append(row,
+					number(c.delta*100, c.present && c.speedup > 0),
+					number(c.speedup, c.present && c.speedup > 0),
+					number(c.p, c.present),
+				)
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
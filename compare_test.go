@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadResultSetRuns(t *testing.T) {
+	const averages = "Algorithm,SinglePath,Time [ms]\nbfs,true,1.5\n"
+	const runs = "Rows,Cols,Algorithm,SinglePath,Time [ms]\n51,51,bfs,true,1.25\n51,51,bfs,true,1.75\n"
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "one run",
+			files: map[string]string{
+				"averages51x51x2xtest.csv": averages,
+				"runs51x51x2xtest.csv":     runs,
+				"averages101x101x5.csv":    averages,
+			},
+		},
+		{
+			name: "tests differ",
+			files: map[string]string{
+				"averages51x51x2.csv":  averages,
+				"averages51x51x10.csv": averages,
+			},
+			err: "different runs of size 51",
+		},
+		{
+			name: "markers differ",
+			files: map[string]string{
+				"averages51x51x2xfirst.csv": averages,
+				"runs51x51x2xsecond.csv":    runs,
+			},
+			err: "different runs of size 51",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			set, err := loadResultSet(dir)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry := set.entries[resultKey{51, 51, "bfs", true}]
+			if entry == nil || entry.means["time"] != 1.5 || len(entry.samples["time"]) != 2 {
+				t.Errorf("got entry %+v", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// environment describes the machine and build a result was measured on.
+type environment struct {
+	GoVersion  string    `json:"goVersion"`
+	GOOS       string    `json:"goos"`
+	GOARCH     string    `json:"goarch"`
+	GOMAXPROCS int       `json:"gomaxprocs"`
+	NumCPU     int       `json:"numCPU"`
+	CPUModel   string    `json:"cpuModel,omitempty"`
+	Hostname   string    `json:"hostname,omitempty"`
+	GitCommit  string    `json:"gitCommit,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func captureEnvironment(now time.Time) environment {
+	hostname, _ := os.Hostname()
+	return environment{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		CPUModel:   cpuModel(),
+		Hostname:   hostname,
+		GitCommit:  gitCommit(),
+		Timestamp:  now.UTC(),
+	}
+}
+
+// cpuModel reads the processor name from /proc/cpuinfo. x86 kernels report
+// it as "model name", ARM kernels as "Hardware" or, lacking that, only the
+// "CPU implementer" and "CPU part" codes. It is empty where /proc is not
+// available.
+func cpuModel() string {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		key = strings.TrimSpace(key)
+		if !found || fields[key] != "" {
+			continue
+		}
+		fields[key] = strings.TrimSpace(value)
+	}
+
+	switch {
+	case fields["model name"] != "":
+		return fields["model name"]
+	case fields["Hardware"] != "":
+		return fields["Hardware"]
+	case fields["CPU part"] != "":
+		return "implementer " + fields["CPU implementer"] + " part " + fields["CPU part"]
+	}
+	return ""
+}
+
+// gitCommit returns the revision the binary was built from, falling back to
+// asking git when it was not stamped into the build, as with go run.
+func gitCommit() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		revision, modified := "", false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if revision != "" {
+			if modified {
+				revision += "-dirty"
+			}
+			return revision
+		}
+	}
+
+	output, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// resolvedExperimentFile is the name of the resolved experiment written to
+// the output directory of an experiment file.
+const resolvedExperimentFile = "experiment.json"
+
+// experiment describes a benchmark run. The command line flags fill one in,
+// and experiment files in YAML, TOML or JSON describe a matrix of
+// generators and loop densities or braids, each of which is swept over the
+// sizes.
+type experiment struct {
+	Name   string `json:"name,omitempty" yaml:"name" toml:"name"`
+	Output string `json:"output" yaml:"output" toml:"output"`
+	Marker string `json:"marker,omitempty" yaml:"marker" toml:"marker"`
+	// Seed is the master seed, random if unset. Zero is a valid seed.
+	Seed *int64 `json:"seed" yaml:"seed" toml:"seed"`
+	// Sizes are sweep specifications as accepted by -sizes.
+	Sizes []string `json:"sizes" yaml:"sizes" toml:"sizes"`
+	// Tests is the number of tests per size, changed to TestsFrom[size]
+	// from that size on.
+	Tests     int            `json:"tests" yaml:"tests" toml:"tests"`
+	TestsFrom map[string]int `json:"testsFrom,omitempty" yaml:"testsFrom" toml:"testsFrom"`
+
+	Algorithms []string `json:"algorithms" yaml:"algorithms" toml:"algorithms"`
+	// Heuristics are extra A* heuristics, added to Algorithms.
+	Heuristics    []string  `json:"heuristics,omitempty" yaml:"heuristics" toml:"heuristics"`
+	Generators    []string  `json:"generators" yaml:"generators" toml:"generators"`
+	LoopDensities []float64 `json:"loopDensities,omitempty" yaml:"loopDensities" toml:"loopDensities"`
+	// Braids are fractions of dead ends removed, and Loops a number of loops
+	// added to every maze with multiple paths, in place of LoopDensities.
+	Braids []float64 `json:"braids,omitempty" yaml:"braids" toml:"braids"`
+	Loops  int       `json:"loops,omitempty" yaml:"loops" toml:"loops"`
+
+	Terrain         bool   `json:"terrain" yaml:"terrain" toml:"terrain"`
+	Connectivity    int    `json:"connectivity" yaml:"connectivity" toml:"connectivity"`
+	NoCornerCutting bool   `json:"noCornerCutting" yaml:"noCornerCutting" toml:"noCornerCutting"`
+	Mode            string `json:"mode" yaml:"mode" toml:"mode"`
+	Workers         int    `json:"workers,omitempty" yaml:"workers" toml:"workers"`
+	Warmup          int    `json:"warmup" yaml:"warmup" toml:"warmup"`
+	LockThread      bool   `json:"lockThread" yaml:"lockThread" toml:"lockThread"`
+
+	OutlierMAD float64  `json:"outlierMAD" yaml:"outlierMAD" toml:"outlierMAD"`
+	Format     string   `json:"format" yaml:"format" toml:"format"`
+	Runs       []string `json:"runs,omitempty" yaml:"runs" toml:"runs"`
+	Report     bool     `json:"report" yaml:"report" toml:"report"`
+
+	// TimeBudget is a duration like "2h30m" for the whole experiment.
+	TimeBudget   string  `json:"timeBudget,omitempty" yaml:"timeBudget" toml:"timeBudget"`
+	MemoryBudget float64 `json:"memoryBudget,omitempty" yaml:"memoryBudget" toml:"memoryBudget"`
+}
+
+// defaultExperiment returns the settings of a run without flags.
+func defaultExperiment() experiment {
+	return experiment{
+		Sizes:        []string{defaultSizes},
+		Tests:        10,
+		Algorithms:   algorithms.Names(),
+		Generators:   []string{maze.DefaultGenerator},
+		Connectivity: 4,
+		Mode:         parallelMode,
+		OutlierMAD:   stats.DefaultMADThreshold,
+		Format:       bothFormat,
+	}
+}
+
+// loadExperiment reads an experiment file, in the format given by its
+// extension, over the defaults. Unknown keys are errors so that typos do not
+// silently fall back to defaults.
+func loadExperiment(filename string) (experiment, error) {
+	e := defaultExperiment()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return e, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&e)
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&e)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&e)
+	default:
+		return e, fmt.Errorf("%s: unknown experiment format %q, want .yaml, .toml or .json", filename, ext)
+	}
+	if err != nil {
+		return e, fmt.Errorf("%s: %w", filename, err)
+	}
+	return e, nil
+}
+
+// experimentCell is one combination of the matrix of an experiment.
+type experimentCell struct {
+	generator   string
+	loopDensity float64
+	braid       float64
+	loops       int
+}
+
+// name identifies the cell in directory names.
+func (c experimentCell) name() string {
+	name := c.generator
+	if c.loopDensity > 0 {
+		name += fmt.Sprintf("_loops%g", c.loopDensity)
+	}
+	if c.braid > 0 {
+		name += fmt.Sprintf("_braid%g", c.braid)
+	}
+	if c.loops > 0 {
+		name += fmt.Sprintf("_added%d", c.loops)
+	}
+	return name
+}
+
+func (c experimentCell) String() string {
+	s := "generator " + c.generator
+	if c.loopDensity > 0 {
+		s += fmt.Sprintf(" with loop density %g", c.loopDensity)
+	}
+	if c.braid > 0 {
+		s += fmt.Sprintf(" with braid %g", c.braid)
+	}
+	if c.loops > 0 {
+		s += fmt.Sprintf(" with %d added loops", c.loops)
+	}
+	return s
+}
+
+// resolvedExperiment is a validated experiment ready to run.
+type resolvedExperiment struct {
+	experiment
+	base   runConfig
+	sizes  sweep
+	tests  testCounts
+	budget budget
+	// randomSeed reports whether the seed was drawn at random, in which
+	// case resuming takes the seed of the journal instead.
+	randomSeed bool
+}
+
+// resolve validates e, fills in the random seed, the number of workers and
+// the algorithms of the heuristics, and parses the sweep.
+func (e experiment) resolve() (*resolvedExperiment, error) {
+	if e.Output == "" {
+		return nil, fmt.Errorf("output directory must be specified with the -o flag")
+	}
+	randomSeed := e.Seed == nil
+	if randomSeed {
+		seed := time.Now().UnixNano()
+		e.Seed = &seed
+	}
+
+	e.Algorithms = slices.Clone(e.Algorithms)
+	for _, heuristic := range e.Heuristics {
+		name, err := algorithms.RegisterAstar(heuristic)
+		if err != nil {
+			return nil, fmt.Errorf("%s, available: %s", err, strings.Join(algorithms.HeuristicNames(), ", "))
+		}
+		if !slices.Contains(e.Algorithms, name) {
+			e.Algorithms = append(e.Algorithms, name)
+		}
+	}
+	if len(e.Algorithms) == 0 {
+		return nil, fmt.Errorf("no algorithms to run")
+	}
+	for _, algorithm := range e.Algorithms {
+		if _, exists := algorithms.Describe(algorithm); !exists {
+			return nil, fmt.Errorf("unknown algorithm %q, available: %s", algorithm, strings.Join(algorithms.Names(), ", "))
+		}
+	}
+
+	if len(e.Generators) == 0 {
+		return nil, fmt.Errorf("no generators to run")
+	}
+	for _, generator := range e.Generators {
+		if _, err := maze.NewGenerator(generator); err != nil {
+			return nil, fmt.Errorf("%s, available: %s", err, strings.Join(maze.GeneratorNames(), ", "))
+		}
+	}
+	if e.Loops < 0 {
+		return nil, fmt.Errorf("loops must not be negative")
+	}
+	for _, braid := range e.Braids {
+		if braid <= 0 || braid > 1 {
+			return nil, fmt.Errorf("braid must be in (0, 1], got %g", braid)
+		}
+	}
+	if len(e.Braids) > 0 || e.Loops > 0 {
+		if len(e.LoopDensities) > 0 {
+			return nil, fmt.Errorf("loop densities cannot be combined with braids or loops")
+		}
+	} else if len(e.LoopDensities) == 0 {
+		e.LoopDensities = []float64{maze.DefaultLoopDensity}
+	}
+	for _, density := range e.LoopDensities {
+		if density <= 0 || density > 1 {
+			return nil, fmt.Errorf("loop density must be in (0, 1], got %g", density)
+		}
+	}
+
+	if e.Connectivity != 4 && e.Connectivity != 8 {
+		return nil, fmt.Errorf("connectivity must be 4 or 8")
+	}
+
+	if e.Mode != parallelMode && e.Mode != isolatedMode && e.Mode != subprocessMode && e.Mode != throughputMode {
+		return nil, fmt.Errorf("mode must be %s, %s, %s or %s", parallelMode, isolatedMode, subprocessMode, throughputMode)
+	}
+	if e.Workers < 0 {
+		return nil, fmt.Errorf("workers must not be negative")
+	}
+	if e.Workers > 0 {
+		if e.Mode != parallelMode && e.Mode != throughputMode {
+			return nil, fmt.Errorf("workers are only supported in %s mode", throughputMode)
+		}
+		e.Mode = throughputMode
+	}
+	if e.Mode == throughputMode && e.Workers == 0 {
+		e.Workers = runtime.NumCPU()
+	}
+
+	if e.Format != csvFormat && e.Format != jsonFormat && e.Format != bothFormat {
+		return nil, fmt.Errorf("format must be %s, %s or %s", csvFormat, jsonFormat, bothFormat)
+	}
+	if e.Report && e.Format == csvFormat {
+		return nil, fmt.Errorf("report needs format %s or %s", jsonFormat, bothFormat)
+	}
+	formats, err := parseRunsFormats(strings.Join(e.Runs, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &resolvedExperiment{experiment: e, randomSeed: randomSeed}
+	if r.sizes, err = parseSizes(strings.Join(e.Sizes, ",")); err != nil {
+		return nil, err
+	}
+	testsSpec := strconv.Itoa(e.Tests)
+	for size, tests := range e.TestsFrom {
+		testsSpec += fmt.Sprintf(",%s=%d", size, tests)
+	}
+	if r.tests, err = parseTestCounts(testsSpec); err != nil {
+		return nil, err
+	}
+	if e.TimeBudget != "" {
+		if r.budget.wallTime, err = time.ParseDuration(e.TimeBudget); err != nil {
+			return nil, fmt.Errorf("invalid time budget: %w", err)
+		}
+	}
+	r.budget.memoryMB = e.MemoryBudget
+
+	r.base = runConfig{
+		outputDir:  e.Output,
+		marker:     e.Marker,
+		experiment: e.Name,
+		seed:       *e.Seed,
+		algorithms: e.Algorithms,
+		terrain:    e.Terrain,
+		movement: algorithms.Movement{
+			Connectivity:    algorithms.Connectivity(e.Connectivity),
+			NoCornerCutting: e.NoCornerCutting,
+		},
+		mode:         e.Mode,
+		warmup:       e.Warmup,
+		workers:      e.Workers,
+		lockThread:   e.LockThread,
+		madThreshold: e.OutlierMAD,
+		format:       e.Format,
+		runsFormats:  formats,
+		report:       e.Report,
+	}
+	return r, nil
+}
+
+// cells expands the matrix of the experiment.
+func (r *resolvedExperiment) cells() []experimentCell {
+	var cells []experimentCell
+	for _, generator := range r.Generators {
+		for _, density := range r.LoopDensities {
+			cells = append(cells, experimentCell{generator: generator, loopDensity: density})
+		}
+		for _, braid := range r.Braids {
+			cells = append(cells, experimentCell{generator: generator, braid: braid, loops: r.Loops})
+		}
+		if len(r.Braids) == 0 && r.Loops > 0 {
+			cells = append(cells, experimentCell{generator: generator, loops: r.Loops})
+		}
+	}
+	return cells
+}
+
+// config returns the run configuration of a cell. With more than one cell,
+// every cell writes to its own subdirectory of the output directory.
+func (r *resolvedExperiment) config(cell experimentCell) runConfig {
+	cfg := r.base
+	cfg.generator = cell.generator
+	cfg.loopDensity = cell.loopDensity
+	cfg.braid = cell.braid
+	cfg.loops = cell.loops
+	if len(r.cells()) > 1 {
+		cfg.outputDir = filepath.Join(cfg.outputDir, cell.name())
+	}
+	return cfg
+}
+
+// resumeSeed replaces a seed drawn at random by the seed of the journal of
+// the first cell that has one, since a resumed run has to generate the
+// same mazes. Explicit seeds are kept and checked by openJournal.
+func (r *resolvedExperiment) resumeSeed() error {
+	if !r.randomSeed {
+		return nil
+	}
+	for _, cell := range r.cells() {
+		header, err := readJournalHeader(r.config(cell).outputDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r.Seed = &header.Seed
+		r.base.seed = header.Seed
+		return nil
+	}
+	return nil
+}
+
+// writeResolved stores the resolved experiment in the output directory.
+func (r *resolvedExperiment) writeResolved() error {
+	data, err := json.MarshalIndent(r.experiment, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.Output, resolvedExperimentFile), append(data, '\n'), 0o644)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadExperimentFormats(t *testing.T) {
+	files := map[string]string{
+		"e.yaml": "output: out\nsizes: [\"25:101:25\"]\ntests: 5\ntestsFrom:\n  \"101\": 2\ngenerators: [prim]\nloopDensities: [0.05, 0.2]\n",
+		"e.toml": "output = \"out\"\nsizes = [\"25:101:25\"]\ntests = 5\ngenerators = [\"prim\"]\nloopDensities = [0.05, 0.2]\n[testsFrom]\n101 = 2\n",
+		"e.json": `{"output": "out", "sizes": ["25:101:25"], "tests": 5, "testsFrom": {"101": 2}, "generators": ["prim"], "loopDensities": [0.05, 0.2]}`,
+	}
+	want := defaultExperiment()
+	want.Output = "out"
+	want.Sizes = []string{"25:101:25"}
+	want.Tests = 5
+	want.TestsFrom = map[string]int{"101": 2}
+	want.Generators = []string{"prim"}
+	want.LoopDensities = []float64{0.05, 0.2}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := loadExperiment(filename)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+
+	filename := filepath.Join(dir, "typo.yaml")
+	os.WriteFile(filename, []byte("output: out\ngenerator: prim\n"), 0o644)
+	if _, err := loadExperiment(filename); err == nil {
+		t.Error("loadExperiment accepted an unknown key")
+	}
+}
+
+func TestResolveExperiment(t *testing.T) {
+	e := defaultExperiment()
+	e.Output = "out"
+	e.Generators = []string{"recursiveBacktracker", "prim"}
+	e.LoopDensities = []float64{0.1, 0.2}
+	e.Tests = 5
+	e.TestsFrom = map[string]int{"101": 2}
+	r, err := e.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Seed == nil {
+		t.Error("the seed was not resolved")
+	}
+
+	zero := int64(0)
+	e.Seed = &zero
+	if r, err := e.resolve(); err != nil || r.base.seed != 0 {
+		t.Errorf("seed 0 was not kept: %v", err)
+	}
+	if got := r.tests.forSize(sweepSize{101, 101}); got != 2 {
+		t.Errorf("tests for size 101 = %d, want 2", got)
+	}
+	cells := r.cells()
+	if len(cells) != 4 {
+		t.Fatalf("got %d cells, want 4", len(cells))
+	}
+	if dir := r.config(cells[3]).outputDir; dir != filepath.Join("out", "prim_loops0.2") {
+		t.Errorf("output directory of the last cell = %q", dir)
+	}
+
+	e = defaultExperiment()
+	e.Output = "out"
+	e.Braids = []float64{0.5, 1}
+	e.Loops = 3
+	if r, err = e.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	cells = r.cells()
+	if len(cells) != 2 {
+		t.Fatalf("got %d braided cells, want 2", len(cells))
+	}
+	cfg := r.config(cells[1])
+	if cfg.braid != 1 || cfg.loops != 3 || cfg.loopDensity != 0 {
+		t.Errorf("braided cell config: braid %g, loops %d, loop density %g", cfg.braid, cfg.loops, cfg.loopDensity)
+	}
+	if want := filepath.Join("out", "recursiveBacktracker_braid1_added3"); cfg.outputDir != want {
+		t.Errorf("output directory of the braided cell = %q, want %q", cfg.outputDir, want)
+	}
+
+	for _, broken := range []func(e *experiment){
+		func(e *experiment) { e.Output = "" },
+		func(e *experiment) { e.Algorithms = []string{"nope"} },
+		func(e *experiment) { e.LoopDensities = []float64{1.5} },
+		func(e *experiment) { e.Braids = []float64{0} },
+		func(e *experiment) { e.Loops = -1 },
+		func(e *experiment) { e.LoopDensities = []float64{0.1}; e.Braids = []float64{0.5} },
+		func(e *experiment) { e.Mode = isolatedMode; e.Workers = 2 },
+		func(e *experiment) { e.TimeBudget = "soon" },
+	} {
+		e := defaultExperiment()
+		e.Output = "out"
+		broken(&e)
+		if _, err := e.resolve(); err == nil {
+			t.Errorf("resolve accepted %+v", e)
+		}
+	}
+}
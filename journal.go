@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// journalFile is the name of the checkpoint journal in the output directory.
+const journalFile = "journal.jsonl"
+
+// errInterrupted is returned by collectMetrics when the run was interrupted
+// between two mazes.
+var errInterrupted = errors.New("interrupted")
+
+// journalHeader is the first line of a journal. A journal is only resumed
+// with the settings it was written with, since they decide the mazes and
+// what is measured.
+type journalHeader struct {
+	Seed            int64   `json:"seed"`
+	Generator       string  `json:"generator"`
+	Terrain         bool    `json:"terrain"`
+	LoopDensity     float64 `json:"loopDensity"`
+	Braid           float64 `json:"braid"`
+	Loops           int     `json:"loops"`
+	Connectivity    int     `json:"connectivity"`
+	NoCornerCutting bool    `json:"noCornerCutting"`
+	Mode            string  `json:"mode"`
+	Warmup          int     `json:"warmup"`
+}
+
+// journalKey identifies one run of one algorithm.
+type journalKey struct {
+	rows, cols int
+	singlePath bool
+	test       int
+	algorithm  string
+}
+
+// journalEntry is a completed run, with the metrics as measured.
+type journalEntry struct {
+	Rows              int     `json:"rows"`
+	Cols              int     `json:"cols"`
+	SinglePath        bool    `json:"singlePath"`
+	Test              int     `json:"test"`
+	Algorithm         string  `json:"algorithm"`
+	Seed              int64   `json:"seed"`
+	Time              float64 `json:"time"`
+	VisitedNodes      int     `json:"visitedNodes"`
+	VisitedPercentage float64 `json:"visitedPercentage"`
+	PathLength        int     `json:"pathLength"`
+	PathCost          float64 `json:"pathCost"`
+	MemoryUsed        float64 `json:"memoryUsed"`
+	Allocs            uint64  `json:"allocs"`
+	WallTime          float64 `json:"wallTime"`
+	PeakRSS           float64 `json:"peakRSS"`
+	PageFaults        int64   `json:"pageFaults"`
+	maze.Stats
+	Verified bool `json:"verified"`
+	Optimal  bool `json:"optimal"`
+}
+
+func (e journalEntry) key() journalKey {
+	return journalKey{e.Rows, e.Cols, e.SinglePath, e.Test, e.Algorithm}
+}
+
+// journal checkpoints every solved maze so that an interrupted or crashed
+// run can be resumed without repeating completed runs.
+type journal struct {
+	file    *os.File
+	entries map[journalKey]journalEntry
+}
+
+func newJournalHeader(cfg runConfig) journalHeader {
+	return journalHeader{
+		Seed:            cfg.seed,
+		Generator:       cfg.generator,
+		Terrain:         cfg.terrain,
+		LoopDensity:     cfg.loopDensity,
+		Braid:           cfg.braid,
+		Loops:           cfg.loops,
+		Connectivity:    int(cfg.movement.Connectivity),
+		NoCornerCutting: cfg.movement.NoCornerCutting,
+		Mode:            cfg.mode,
+		Warmup:          cfg.warmup,
+	}
+}
+
+// openJournal starts a new journal in the output directory or, when
+// resuming, loads the runs of the existing one and appends to it. An
+// existing journal is only replaced with force, so that forgetting -resume
+// does not throw away hours of runs.
+func openJournal(cfg runConfig, resume, force bool) (*journal, error) {
+	filename := filepath.Join(cfg.outputDir, journalFile)
+	header := newJournalHeader(cfg)
+	j := &journal{entries: make(map[journalKey]journalEntry)}
+
+	if resume {
+		file, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND, 0)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			j.file = file
+			if err := j.load(header); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+			return j, nil
+		}
+	}
+
+	flags := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	file, err := os.OpenFile(filename, flags, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%s already exists, continue it with -resume or replace it with -force", filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	j.file = file
+	if err := j.write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// readJournalHeader reads the header of the journal in dir.
+func readJournalHeader(dir string) (journalHeader, error) {
+	var header journalHeader
+	file, err := os.Open(filepath.Join(dir, journalFile))
+	if err != nil {
+		return header, err
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil {
+		return header, fmt.Errorf("%s: reading header: %w", file.Name(), err)
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return header, fmt.Errorf("%s: reading header: %w", file.Name(), err)
+	}
+	return header, nil
+}
+
+// load reads the runs of the journal after checking its header. A last line
+// cut short by a crash is dropped, so that new runs start on a fresh line.
+func (j *journal) load(want journalHeader) error {
+	reader := bufio.NewReader(j.file)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	var header journalHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	if !reflect.DeepEqual(header, want) {
+		return fmt.Errorf("journal was written with different settings %+v, want %+v", header, want)
+	}
+
+	offset := int64(len(line))
+	for {
+		line, err := reader.ReadBytes('\n')
+		var entry journalEntry
+		if err != nil || json.Unmarshal(line, &entry) != nil {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			break
+		}
+		j.entries[entry.key()] = entry
+		offset += int64(len(line))
+	}
+	return j.file.Truncate(offset)
+}
+
+// write appends one line and syncs it to disk.
+func (j *journal) write(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// lookup returns the journaled run, if any.
+func (j *journal) lookup(key journalKey) (journalEntry, bool) {
+	if j == nil {
+		return journalEntry{}, false
+	}
+	entry, exists := j.entries[key]
+	return entry, exists
+}
+
+// record journals the last run of the given algorithms.
+func (j *journal) record(rows, cols int, singlePath bool, test int, algorithms []string, metrics map[string]*Metrics) error {
+	if j == nil {
+		return nil
+	}
+	for _, algorithm := range algorithms {
+		m := metrics[algorithm]
+		i := len(m.Time) - 1
+		entry := journalEntry{
+			Rows:              rows,
+			Cols:              cols,
+			SinglePath:        singlePath,
+			Test:              test,
+			Algorithm:         algorithm,
+			Seed:              m.Seeds[i],
+			Time:              m.Time[i],
+			VisitedNodes:      m.VisitedNodes[i],
+			VisitedPercentage: m.VisitedPercentage[i],
+			PathLength:        m.PathLength[i],
+			PathCost:          m.PathCost[i],
+			MemoryUsed:        m.MemoryUsed[i],
+			Allocs:            m.Allocs[i],
+			WallTime:          m.WallTime[i],
+			PeakRSS:           m.PeakRSS[i],
+			PageFaults:        m.PageFaults[i],
+			Stats:             m.Mazes[i],
+			Verified:          m.Verified[i],
+			Optimal:           m.Optimal[i],
+		}
+		if err := j.write(entry); err != nil {
+			return err
+		}
+		j.entries[entry.key()] = entry
+	}
+	return nil
+}
+
+// restore appends a journaled run to the metrics of its algorithm.
+func (e journalEntry) restore(m *Metrics) {
+	m.Time = append(m.Time, e.Time)
+	m.VisitedNodes = append(m.VisitedNodes, e.VisitedNodes)
+	m.VisitedPercentage = append(m.VisitedPercentage, e.VisitedPercentage)
+	m.PathLength = append(m.PathLength, e.PathLength)
+	m.PathCost = append(m.PathCost, e.PathCost)
+	m.MemoryUsed = append(m.MemoryUsed, e.MemoryUsed)
+	m.Allocs = append(m.Allocs, e.Allocs)
+	m.WallTime = append(m.WallTime, e.WallTime)
+	m.PeakRSS = append(m.PeakRSS, e.PeakRSS)
+	m.PageFaults = append(m.PageFaults, e.PageFaults)
+	m.Seeds = append(m.Seeds, e.Seed)
+	m.Mazes = append(m.Mazes, e.Stats)
+	m.Verified = append(m.Verified, e.Verified)
+	m.Optimal = append(m.Optimal, e.Optimal)
+}
+
+func (j *journal) close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// remove closes and deletes the journal of a finished run, which leaves
+// nothing to resume and must not block the next run into the directory.
+func (j *journal) remove() error {
+	if err := j.close(); err != nil {
+		return err
+	}
+	return os.Remove(j.file.Name())
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+func journalTestConfig(t *testing.T, mode string) runConfig {
+	t.Helper()
+	return runConfig{
+		outputDir:    t.TempDir(),
+		seed:         3,
+		algorithms:   []string{"dijkstra", "astar", "bfs"},
+		generator:    "recursiveBacktracker",
+		mode:         mode,
+		workers:      2,
+		madThreshold: stats.DefaultMADThreshold,
+		format:       bothFormat,
+		quiet:        true,
+	}
+}
+
+// interrupted returns a closed interrupt channel, which makes collectMetrics
+// stop before the first maze that is not in the journal.
+func interrupted() <-chan struct{} {
+	interrupt := make(chan struct{})
+	close(interrupt)
+	return interrupt
+}
+
+func TestJournalRoundTrip(t *testing.T) {
+	cfg := journalTestConfig(t, isolatedMode)
+	j, err := openJournal(cfg, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.journal = j
+	metricsSPOn, metricsSPOff, err := collectMetrics(11, 11, 2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash in the middle of a line leaves it cut short.
+	filename := filepath.Join(cfg.outputDir, journalFile)
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"rows":11,"cols":11,"singlePath":tr`)
+	file.Close()
+
+	resumed, err := openJournal(cfg, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.close()
+	if want := 2 * 2 * len(cfg.algorithms); len(resumed.entries) != want {
+		t.Fatalf("resumed %d runs, want %d", len(resumed.entries), want)
+	}
+
+	// Every run is restored, so nothing is solved and the interrupt is never
+	// seen.
+	cfg.journal = resumed
+	cfg.interrupt = interrupted()
+	restoredSPOn, restoredSPOff, err := collectMetrics(11, 11, 2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restoredSPOn, metricsSPOn) || !reflect.DeepEqual(restoredSPOff, metricsSPOff) {
+		t.Error("restored metrics differ from the journaled ones")
+	}
+}
+
+func TestJournalResume(t *testing.T) {
+	// The sequential modes stop at the first missing maze, before the
+	// journaled mazes with multiple paths, while throughput mode restores
+	// every journaled maze.
+	tests := []struct {
+		mode         string
+		multipleRuns int
+	}{
+		{isolatedMode, 0},
+		{throughputMode, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			cfg := journalTestConfig(t, test.mode)
+			j, err := openJournal(cfg, false, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.journal = j
+			if _, _, err := collectMetrics(11, 11, 2, cfg); err != nil {
+				t.Fatal(err)
+			}
+			j.close()
+
+			resumed, err := openJournal(cfg, true, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resumed.close()
+			cfg.journal = resumed
+			cfg.interrupt = interrupted()
+
+			// Asking for a third test stops at its first maze with the
+			// journaled tests restored so far.
+			metricsSPOn, metricsSPOff, err := collectMetrics(11, 11, 3, cfg)
+			if err != errInterrupted {
+				t.Fatalf("got error %v, want %v", err, errInterrupted)
+			}
+			for _, algorithm := range cfg.algorithms {
+				if n := len(metricsSPOn[algorithm].Time); n != 2 {
+					t.Errorf("%s: %d single path runs, want 2", algorithm, n)
+				}
+				if n := len(metricsSPOn[algorithm].Suboptimal); n != 2 {
+					t.Errorf("%s: %d single path suboptimal flags, want 2", algorithm, n)
+				}
+				if n := len(metricsSPOff[algorithm].Time); n != test.multipleRuns {
+					t.Errorf("%s: %d multiple path runs, want %d", algorithm, n, test.multipleRuns)
+				}
+			}
+
+			if err := writePartialResults(11, 11, 3, cfg, metricsSPOn, metricsSPOff); err != nil {
+				t.Fatal(err)
+			}
+			document, err := readResultDocument(filepath.Join(cfg.outputDir, "partial11x11x3.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := len(cfg.algorithms)
+			if test.multipleRuns > 0 {
+				want *= 2
+			}
+			if len(document.Results) != want {
+				t.Errorf("partial results have %d entries, want %d", len(document.Results), want)
+			}
+			for _, result := range document.Results {
+				if n := result.Metrics["visitedNodes"].N; n != 2 {
+					t.Errorf("partial %s: %d runs, want 2", result.Algorithm, n)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenJournalExisting(t *testing.T) {
+	cfg := journalTestConfig(t, isolatedMode)
+	j, err := openJournal(cfg, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.close()
+
+	if _, err := openJournal(cfg, false, false); err == nil {
+		t.Error("an existing journal was replaced without -force")
+	}
+	j, err = openJournal(cfg, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.close()
+
+	cfg.seed++
+	if _, err := openJournal(cfg, true, false); err == nil {
+		t.Error("a journal written with another seed was resumed")
+	}
+}
+
+func TestResumeRandomSeed(t *testing.T) {
+	e := defaultExperiment()
+	e.Output = t.TempDir()
+	r, err := e.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell := r.cells()[0]
+	j, err := openJournal(r.config(cell), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.close()
+
+	// Resolving again draws another seed, which resuming replaces by the
+	// seed of the journal.
+	resumed, err := e.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.base.seed++
+	if err := resumed.resumeSeed(); err != nil {
+		t.Fatal(err)
+	}
+	if *resumed.Seed != *r.Seed || resumed.base.seed != *r.Seed {
+		t.Fatalf("resumed with seed %d, want %d", resumed.base.seed, *r.Seed)
+	}
+	j, err = openJournal(resumed.config(cell), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.close()
+
+	// An explicit seed is kept, so a different one is refused.
+	seed := *r.Seed + 1
+	e.Seed = &seed
+	if resumed, err = e.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.resumeSeed(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openJournal(resumed.config(cell), true, false); err == nil {
+		t.Error("a journal written with another seed was resumed")
+	}
+}
+
+func TestRunCellRemovesJournal(t *testing.T) {
+	cfg := journalTestConfig(t, isolatedMode)
+	args := []string{"11", "2"}
+	for range 2 {
+		if err := runCell(cfg, false, false, args, nil, testCounts{}, budget{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(cfg.outputDir, journalFile)); !os.IsNotExist(err) {
+			t.Fatalf("journal of a finished run was kept: %v", err)
+		}
+	}
+
+	// An interrupted run keeps its journal and blocks the next run.
+	cfg.interrupt = interrupted()
+	if err := runCell(cfg, false, false, args, nil, testCounts{}, budget{}); err != errInterrupted {
+		t.Fatalf("got error %v, want %v", err, errInterrupted)
+	}
+	cfg.interrupt = nil
+	if err := runCell(cfg, false, false, args, nil, testCounts{}, budget{}); err == nil {
+		t.Error("an unfinished journal was replaced without -force")
+	}
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"runtime"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"pathfinding_algorithms_test_runner/algorithms" //"runtime/pprof"
 	"pathfinding_algorithms_test_runner/maze"
+	"pathfinding_algorithms_test_runner/verify"
 )
 
 type Metrics struct {
@@ -20,211 +25,708 @@ type Metrics struct {
 	VisitedNodes      []int
 	VisitedPercentage []float64
 	PathLength        []int
+	PathCost          []float64
 	MemoryUsed        []float64
+	Allocs            []uint64
+	// WallTime, PeakRSS and PageFaults are only measured in subprocess mode.
+	WallTime   []float64
+	PeakRSS    []float64
+	PageFaults []int64
+	Seeds      []int64
+	// Mazes describes the maze of each run.
+	Mazes []maze.Stats
+	// Suboptimal records per run whether the path cost more than Dijkstra's.
+	Suboptimal []bool
+	// Verified records per run whether the path passed verify.Check, and
+	// Optimal whether it was a shortest path.
+	Verified []bool
+	Optimal  []bool
 }
 
-var algorithmsMap = map[string]algorithms.Algorithm{
-	"dijkstra":     algorithms.Dijkstra{},
-	"astar":        algorithms.Astar{},
-	"bfs":          algorithms.BFS{},
-	"dfs":          algorithms.DFS{},
-	"wallFollower": algorithms.WallFollower{},
+// runConfig holds the settings shared by every test of a run.
+type runConfig struct {
+	outputDir string
+	marker    string
+	// experiment names the experiment file the run belongs to, if any.
+	experiment string
+	seed       int64
+	algorithms []string
+	generator  string
+	terrain    bool
+	// loopDensity is the fraction of the cells opened in mazes with
+	// multiple paths, unless braid or loops replace them.
+	loopDensity float64
+	braid       float64
+	loops       int
+	movement    algorithms.Movement
+	mode        string
+	warmup      int
+	lockThread  bool
+	// workers is the number of mazes solved concurrently in throughputMode.
+	workers int
+	// madThreshold is the robust z-score above which a run is counted as
+	// an outlier.
+	madThreshold float64
+	// format selects the averages CSV, the JSON result document or both.
+	format string
+	// runsFormats are the formats of the per-run export, none if empty.
+	runsFormats []string
+	// quiet suppresses the progress output of every solved maze.
+	quiet bool
+	// journal checkpoints every solved maze, nil if runs are not journaled.
+	journal *journal
+	// interrupt is closed when the run should stop after the current maze.
+	interrupt <-chan struct{}
+	// report regenerates report.html in the output directory after every
+	// maze size.
+	report bool
+	// memory records the peak memory of the current size of a sweep, nil
+	// outside of sweeps.
+	memory *memorySampler
+}
+
+// randomSeed is the -seed value that draws a random master seed, so that
+// every integer including zero is a reproducible seed.
+const randomSeed = "random"
+
+// commands are the subcommands selected by the first argument; without one
+// the runner benchmarks.
+var commands = map[string]func(args []string) error{
+	"compare": runCompare,
+	"check":   runCheck,
+	"maze":    runMaze,
+	"plot":    runPlot,
+	"report":  runReport,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if command, exists := commands[os.Args[1]]; exists {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	defaults := defaultExperiment()
+	configFlag := flag.String("config", "", "Experiment file in YAML, TOML or JSON; other flags except -resume and -force cannot be combined with it")
 	nFlag := flag.String("n", "", "Optional filename marker")
 	oFlag := flag.String("o", "", "Output directory (required)")
+	seedFlag := flag.String("seed", randomSeed, "Master seed for maze generation, or "+randomSeed)
+	algorithmsFlag := flag.String(
+		"algorithms",
+		strings.Join(defaults.Algorithms, ","),
+		"Comma-separated list of algorithms to run",
+	)
+	heuristicsFlag := flag.String(
+		"heuristics",
+		"",
+		"Comma-separated extra A* heuristics to benchmark, e.g. weighted-manhattan:1.5 (available: "+
+			strings.Join(algorithms.HeuristicNames(), ", ")+")",
+	)
+	generatorFlag := flag.String(
+		"generator",
+		maze.DefaultGenerator,
+		"Maze generator, one of: "+strings.Join(maze.GeneratorNames(), ", "),
+	)
+	loopDensityFlag := flag.Float64("loop-density", maze.DefaultLoopDensity, "Fraction of the cells opened to create extra paths in mazes with multiple paths")
+	braidFlag := flag.Float64("braid", 0, "Fraction of the dead ends removed from mazes with multiple paths, in (0, 1], instead of -loop-density")
+	loopsFlag := flag.Int("loops", 0, "Loops added to mazes with multiple paths after braiding, instead of -loop-density")
+	terrainFlag := flag.Bool("terrain", false, "Assign noise-based movement costs (road, mud, water) to open cells")
+	connectivityFlag := flag.Int("connectivity", defaults.Connectivity, "Movement connectivity, 4 or 8")
+	noCornerCuttingFlag := flag.Bool("no-corner-cutting", false, "Forbid diagonal moves past wall corners")
+	modeFlag := flag.String(
+		"mode",
+		defaults.Mode,
+		"Measurement mode: "+parallelMode+" runs the algorithms of a maze concurrently, "+
+			isolatedMode+" runs them one at a time after a forced GC, "+
+			subprocessMode+" runs each of them in a child process to measure peak RSS, "+
+			throughputMode+" solves many mazes at once on -workers for fast correctness sweeps",
+	)
+	workersFlag := flag.Int("workers", 0, "Mazes solved concurrently in "+throughputMode+" mode, implied by a positive value (0 for one per CPU)")
+	warmupFlag := flag.Int("warmup", 0, "Untimed warm-up runs of each algorithm before every measured run")
+	lockThreadFlag := flag.Bool("lock-thread", false, "Lock the measuring goroutine to its OS thread in "+isolatedMode+" mode")
+	outlierMADFlag := flag.Float64(
+		"outlier-mad",
+		defaults.OutlierMAD,
+		"Flag runs whose distance from the median exceeds this many scaled median absolute deviations",
+	)
+	formatFlag := flag.String(
+		"format",
+		defaults.Format,
+		"Result format: "+csvFormat+" for the averages CSV, "+jsonFormat+" for the versioned JSON document, or "+bothFormat,
+	)
+	runsFlag := flag.String(
+		"runs",
+		"",
+		"Comma-separated formats of a per-run results file written next to the averages ("+
+			strings.Join(runsFormats, ", ")+")",
+	)
+	resumeFlag := flag.Bool("resume", false, "Resume from the journal in the output directory, skipping completed runs")
+	forceFlag := flag.Bool("force", false, "Replace an existing journal in the output directory instead of refusing to start")
+	sizesFlag := flag.String(
+		"sizes",
+		defaultSizes,
+		"Sizes swept without positional arguments: comma-separated sizes (51 or 51x101) and ranges "+
+			"start:stop:step, with a step like x2 for geometric growth and an empty stop for no end",
+	)
+	testsFlag := flag.String(
+		"tests",
+		strconv.Itoa(defaults.Tests),
+		"Tests per size of a sweep, optionally changed from a size on, e.g. 10,201=5,1001=2",
+	)
+	timeBudgetFlag := flag.Duration("time-budget", 0, "Stop a sweep before a size expected to end after this much time (0 for none)")
+	memoryBudgetFlag := flag.Float64("memory-budget", 0, "Stop a sweep before a size expected to need more peak memory in MB (0 for none)")
+	reportFlag := flag.Bool("report", false, "Regenerate report.html in the output directory after every maze size, needs JSON results")
+	childModeFlag := flag.Bool(childFlag, false, "Internal: solve a single job from stdin for "+subprocessMode+" mode")
 	flag.Parse()
 
-	if *oFlag == "" {
-		fmt.Println("Error: Output directory must be specified with the -o flag.")
+	if *childModeFlag {
+		if err := runChild(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var e experiment
+	if *configFlag != "" {
+		var conflicts []string
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name != "config" && f.Name != "resume" && f.Name != "force" {
+				conflicts = append(conflicts, "-"+f.Name)
+			}
+		})
+		if len(conflicts) > 0 || flag.NArg() > 0 {
+			fmt.Printf("Error: %s cannot be combined with -config.\n", strings.Join(append(conflicts, flag.Args()...), " "))
+			os.Exit(1)
+		}
+		var err error
+		if e, err = loadExperiment(*configFlag); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		e = defaults
+		e.Output = *oFlag
+		e.Marker = *nFlag
+		if *seedFlag != randomSeed {
+			seed, err := strconv.ParseInt(*seedFlag, 10, 64)
+			if err != nil {
+				fmt.Printf("Error: invalid seed %q, want an integer or %s\n", *seedFlag, randomSeed)
+				os.Exit(1)
+			}
+			e.Seed = &seed
+		}
+		e.Sizes = []string{*sizesFlag}
+		e.Algorithms = strings.Split(*algorithmsFlag, ",")
+		if *heuristicsFlag != "" {
+			e.Heuristics = strings.Split(*heuristicsFlag, ",")
+		}
+		e.Generators = []string{*generatorFlag}
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "loop-density" {
+				e.LoopDensities = []float64{*loopDensityFlag}
+			}
+		})
+		if *braidFlag != 0 {
+			e.Braids = []float64{*braidFlag}
+		}
+		e.Loops = *loopsFlag
+		e.Terrain = *terrainFlag
+		e.Connectivity = *connectivityFlag
+		e.NoCornerCutting = *noCornerCuttingFlag
+		e.Mode = *modeFlag
+		e.Workers = *workersFlag
+		e.Warmup = *warmupFlag
+		e.LockThread = *lockThreadFlag
+		e.OutlierMAD = *outlierMADFlag
+		e.Format = *formatFlag
+		if *runsFlag != "" {
+			e.Runs = strings.Split(*runsFlag, ",")
+		}
+		e.Report = *reportFlag
+		e.MemoryBudget = *memoryBudgetFlag
+		if *timeBudgetFlag > 0 {
+			e.TimeBudget = timeBudgetFlag.String()
+		}
+	}
+
+	r, err := e.resolve()
+	if err == nil && *configFlag == "" {
+		// -tests takes the full specification, which experiment files
+		// spell as tests and testsFrom.
+		r.tests, err = parseTestCounts(*testsFlag)
+	}
+	if err == nil && *resumeFlag {
+		err = r.resumeSeed()
+	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Using master seed %d\n", *r.Seed)
+
+	if err := os.MkdirAll(r.Output, 0o755); err != nil {
+		fmt.Printf("Error: %s\n", err)
 		os.Exit(1)
 	}
+	if *configFlag != "" {
+		if err := r.writeResolved(); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// The first interrupt stops the run after the current maze; a second one
+	// aborts at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+		fmt.Println("Interrupted, stopping after the current maze")
+	}()
+
+	r.budget.started = time.Now()
+	for _, cell := range r.cells() {
+		cfg := r.config(cell)
+		cfg.interrupt = ctx.Done()
+		if len(r.cells()) > 1 {
+			fmt.Printf("Running %s\n", cell)
+		}
+		err := runCell(cfg, *resumeFlag, *forceFlag, flag.Args(), r.sizes, r.tests, r.budget)
+		if errors.Is(err, errInterrupted) {
+			fmt.Printf("Completed runs are saved in %s, continue with -resume\n", filepath.Join(cfg.outputDir, journalFile))
+			os.Exit(130)
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// runCell runs one cell of an experiment with its own journal.
+func runCell(cfg runConfig, resume, force bool, args []string, sizes sweep, tests testCounts, b budget) error {
+	if err := os.MkdirAll(cfg.outputDir, 0o755); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return err
+	}
+	journal, err := openJournal(cfg, resume, force)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return err
+	}
+	cfg.journal = journal
+	if resume {
+		fmt.Printf("Resuming with %d completed runs from the journal\n", len(journal.entries))
+	}
 
-	args := flag.Args()
+	err = run(cfg, args, sizes, tests, b)
+	if err != nil {
+		journal.close()
+		return err
+	}
+	return journal.remove()
+}
+
+// run benchmarks the size given by the arguments, or sweeps sizes without
+// any.
+func run(cfg runConfig, args []string, sizes sweep, tests testCounts, b budget) error {
 	if len(args) < 2 {
-		runTestsWithIncreasingSize(*nFlag, *oFlag)
-	} else {
-		mazeSize, _ := strconv.Atoi(args[0])
-		numTests, _ := strconv.Atoi(args[1])
-		var marker string
-		if len(args) > 2 {
-			marker = args[2]
+		return runSweep(cfg, sizes, tests, b)
+	}
+
+	size, err := parseSize(args[0])
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return err
+	}
+	numTests, _ := strconv.Atoi(args[1])
+	if len(args) > 2 {
+		cfg.marker = args[2]
+	}
+	if err := runTest(size.rows, size.cols, numTests, cfg); err != nil {
+		if errors.Is(err, errInterrupted) {
+			return err
 		}
-		runTest(mazeSize, numTests, marker, *oFlag)
+		fmt.Printf("Test failed for maze size %s: %s\n", size, err.Error())
+		return err
 	}
+	return nil
 }
 
-func runTestsWithIncreasingSize(marker, outputDir string) {
-	size := 25
-	for {
-		fmt.Printf("Running tests with maze size %d\n", size)
-		err := runTest(size, 10, marker, outputDir)
-		if err != nil {
-			fmt.Printf("Test failed for maze size %d: %s\n", size, err.Error())
-			break
+func runTest(numRows, numCols, numTests int, cfg runConfig) error {
+	metricsSPOn, metricsSPOff, err := collectMetrics(numRows, numCols, numTests, cfg)
+	if errors.Is(err, errInterrupted) && metricsSPOn != nil {
+		if writeErr := writePartialResults(numRows, numCols, numTests, cfg, metricsSPOn, metricsSPOff); writeErr != nil {
+			fmt.Printf("Error: writing partial results: %s\n", writeErr)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	reportHeuristics(cfg, metricsSPOn, metricsSPOff)
+	violations := countOptimalityViolations(cfg, metricsSPOn, metricsSPOff)
+
+	summariesSPOn := calculateSummaries(cfg, metricsSPOn)
+	summariesSPOff := calculateSummaries(cfg, metricsSPOff)
+
+	if numRows%2 == 0 || numCols%2 == 0 {
+		fmt.Println("Sorry! Even mazes aren't supported, so even sides were incremented by 1.")
+	}
+	numRows, numCols = normalizeSize(numRows), normalizeSize(numCols)
+	suffix := resultSuffix(numRows, numCols, numTests, cfg)
+	// Throughput results lack times and memory, and are named apart so that
+	// compare, plot and report do not mistake them for benchmarks.
+	averagesPrefix, runsPrefix := "/averages", "/runs"
+	if cfg.mode == throughputMode {
+		averagesPrefix, runsPrefix = "/throughput", "/throughputRuns"
+	}
+	if err := writeAverages(cfg.outputDir+averagesPrefix+suffix, numRows, numCols, numTests, cfg, summariesSPOn, summariesSPOff); err != nil {
+		return err
+	}
+	// Partial results of an earlier, interrupted run of this size are
+	// superseded.
+	for _, extension := range []string{".csv", ".json"} {
+		if err := os.Remove(cfg.outputDir + "/partial" + suffix + extension); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	runs := collectRuns(cfg, numRows, numCols, metricsSPOn, metricsSPOff)
+	if err := writeRuns(cfg.outputDir+runsPrefix+suffix, cfg, runs); err != nil {
+		return err
+	}
+	if cfg.report {
+		if err := writeReport(cfg.outputDir); err != nil {
+			return err
 		}
-		size += 25
 	}
+
+	if violations > 0 {
+		return fmt.Errorf("%d runs of algorithms claiming optimality returned a longer or invalid path", violations)
+	}
+	return nil
 }
 
-func runTest(mazeSize, numTests int, marker, outputDir string) error {
-	numRows := mazeSize
-	numCols := mazeSize
-	metricsSPOn := initializeMetrics()
-	metricsSPOff := initializeMetrics()
+// resultSuffix is the part of the result file names after the kind of
+// file, e.g. 51x51x10 or 51x51x10xmarker.
+func resultSuffix(numRows, numCols, numTests int, cfg runConfig) string {
+	suffix := fmt.Sprintf("%dx%dx%d", numRows, numCols, numTests)
+	if cfg.marker != "" {
+		suffix += "x" + cfg.marker
+	}
+	return suffix
+}
 
-	// Counter for path length differences
-	differentPathCount := 0
+// writeAverages writes the summaries in the configured formats to filename
+// with the .csv and .json extensions.
+func writeAverages(
+	filename string,
+	numRows, numCols, numTests int,
+	cfg runConfig,
+	summariesSPOn, summariesSPOff map[string]*algorithmSummary,
+) error {
+	if cfg.format != jsonFormat {
+		if err := writeResultsToCsv(filename+".csv", cfg, summariesSPOn, summariesSPOff); err != nil {
+			return err
+		}
+	}
+	if cfg.format != csvFormat {
+		document := newResultDocument(cfg, numRows, numCols, numTests, summariesSPOn, summariesSPOff)
+		if err := writeResultsToJson(filename+".json", document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Test mazes with a single path
-	for i := 0; i < numTests; i++ {
-		grids, startNodes, endNodes := getInitialGrid(numRows, numCols, true)
-		var wg sync.WaitGroup
-		for algorithm := range algorithmsMap {
-			wg.Add(1)
-			go func(algorithm string) {
-				defer wg.Done()
-				runAlgorithm(
-					algorithm,
-					grids[algorithm],
-					startNodes[algorithm],
-					endNodes[algorithm],
-					metricsSPOn,
-				)
-			}(algorithm)
+// writePartialResults writes the averages of the tests completed before an
+// interrupt to partial<suffix>.csv and .json, which compare, plot and report
+// do not read. Algorithms and maze kinds without completed tests are left
+// out. The next complete run of the size removes the files.
+func writePartialResults(numRows, numCols, numTests int, cfg runConfig, metricsSPOn, metricsSPOff map[string]*Metrics) error {
+	completed := func(metrics map[string]*Metrics) map[string]*Metrics {
+		runs := make(map[string]*Metrics)
+		for algorithm, m := range metrics {
+			if len(m.Time) > 0 {
+				runs[algorithm] = m
+			}
 		}
-		wg.Wait()
-		fmt.Printf(
-			"Completed test %d of %d for mazes with a single path for size: %d\n",
-			i+1,
-			numTests,
-			mazeSize,
-		)
-		clearMemory(grids, startNodes, endNodes)
+		return runs
+	}
+	metricsSPOn, metricsSPOff = completed(metricsSPOn), completed(metricsSPOff)
+	if len(metricsSPOn) == 0 && len(metricsSPOff) == 0 {
+		return nil
+	}
+
+	numRows, numCols = normalizeSize(numRows), normalizeSize(numCols)
+	filename := cfg.outputDir + "/partial" + resultSuffix(numRows, numCols, numTests, cfg)
+	fmt.Printf("Writing the averages of the completed tests to %s\n", filename)
+	return writeAverages(
+		filename, numRows, numCols, numTests, cfg,
+		calculateSummaries(cfg, metricsSPOn), calculateSummaries(cfg, metricsSPOff),
+	)
+}
+
+// collectMetrics solves numTests single path and numTests multiple path
+// mazes of the given size with every configured algorithm. Runs found in the
+// journal are restored instead of solved again. When interrupted, it
+// returns the tests completed so far with errInterrupted.
+func collectMetrics(numRows, numCols, numTests int, cfg runConfig) (map[string]*Metrics, map[string]*Metrics, error) {
+	if cfg.mode == throughputMode {
+		return collectMetricsConcurrently(numRows, numCols, numTests, cfg)
 	}
 
+	metricsSPOn := initializeMetrics(cfg.algorithms)
+	metricsSPOff := initializeMetrics(cfg.algorithms)
+
+	// Test mazes with a single path
+	if err := solveTests(numRows, numCols, numTests, true, cfg, metricsSPOn); err != nil {
+		if errors.Is(err, errInterrupted) {
+			return metricsSPOn, metricsSPOff, err
+		}
+		return nil, nil, err
+	}
 	// Test mazes with multiple paths
+	if err := solveTests(numRows, numCols, numTests, false, cfg, metricsSPOff); err != nil {
+		if errors.Is(err, errInterrupted) {
+			return metricsSPOn, metricsSPOff, err
+		}
+		return nil, nil, err
+	}
+
+	return metricsSPOn, metricsSPOff, nil
+}
+
+// solveTests solves numTests mazes of one kind, checking for an interrupt
+// before every maze that still has to be solved.
+func solveTests(numRows, numCols, numTests int, singlePath bool, cfg runConfig, metrics map[string]*Metrics) error {
+	rows, cols := normalizeSize(numRows), normalizeSize(numCols)
 	for i := 0; i < numTests; i++ {
-		grids, startNodes, endNodes := getInitialGrid(numRows, numCols, false)
-		var wg sync.WaitGroup
-		for algorithm := range algorithmsMap {
-			wg.Add(1)
-			go func(algorithm string) {
-				defer wg.Done()
-				runAlgorithm(
-					algorithm,
-					grids[algorithm],
-					startNodes[algorithm],
-					endNodes[algorithm],
-					metricsSPOff,
-				)
-			}(algorithm)
+		var missing []string
+		for _, algorithm := range cfg.algorithms {
+			if entry, exists := cfg.journal.lookup(journalKey{rows, cols, singlePath, i, algorithm}); exists {
+				entry.restore(metrics[algorithm])
+			} else {
+				missing = append(missing, algorithm)
+			}
 		}
-		wg.Wait()
 
-		// Compare path lengths of A* and Dijkstra
-		if metricsSPOff["astar"].PathLength[i] != metricsSPOff["dijkstra"].PathLength[i] {
-			differentPathCount++
+		if len(missing) > 0 {
+			select {
+			case <-cfg.interrupt:
+				return errInterrupted
+			default:
+			}
+
+			layout, err := generateTestMaze(cfg, numRows, numCols, singlePath, i)
+			if err != nil {
+				return err
+			}
+			solveCfg := cfg
+			solveCfg.algorithms = missing
+			solveMaze(solveCfg, layout, metrics)
+			if err := cfg.journal.record(rows, cols, singlePath, i, missing, metrics); err != nil {
+				return err
+			}
 		}
 
+		markSuboptimalRuns(metrics, i)
+		if !cfg.quiet {
+			kind := "with a single path"
+			if !singlePath {
+				kind = "with multiple paths,"
+			}
+			fmt.Printf(
+				"Completed test %d of %d for mazes %s for size: %s\n",
+				i+1,
+				numTests,
+				kind,
+				sweepSize{numRows, numCols},
+			)
+		}
+	}
+	return nil
+}
+
+// generateTestMaze generates the maze of the given test; the test index and
+// kind select its seed.
+func generateTestMaze(cfg runConfig, numRows, numCols int, singlePath bool, test int) (*maze.Layout, error) {
+	seed := maze.DeriveSeed(cfg.seed, 2*test)
+	if !singlePath {
+		seed = maze.DeriveSeed(cfg.seed, 2*test+1)
+	}
+	return maze.Generate(maze.Options{
+		Rows:        numRows,
+		Cols:        numCols,
+		SinglePath:  singlePath,
+		Seed:        seed,
+		Generator:   cfg.generator,
+		Terrain:     cfg.terrain,
+		LoopDensity: cfg.loopDensity,
+		Braid:       cfg.braid,
+		Loops:       cfg.loops,
+	})
+}
+
+// markSuboptimalRuns records for the i-th run of every algorithm whether
+// its path cost more than Dijkstra's on the same maze.
+func markSuboptimalRuns(metrics map[string]*Metrics, i int) {
+	dijkstra, dijkstraExists := metrics["dijkstra"]
+	for _, metric := range metrics {
+		suboptimal := dijkstraExists && metric.PathCost[i] > dijkstra.PathCost[i]+1e-6
+		metric.Suboptimal = append(metric.Suboptimal, suboptimal)
+	}
+}
+
+// reportHeuristics prints how often each A* heuristic produced a path
+// costlier than Dijkstra's, flagging inadmissible heuristics.
+func reportHeuristics(cfg runConfig, metricsSPOn, metricsSPOff map[string]*Metrics) {
+	if _, exists := metricsSPOff["dijkstra"]; !exists {
+		return
+	}
+
+	for _, algorithm := range cfg.algorithms {
+		info, _ := algorithms.Describe(algorithm)
+		if info.Heuristic == "" {
+			continue
+		}
+
+		suboptimalRuns, runs := 0, 0
+		for _, metrics := range []*Metrics{metricsSPOn[algorithm], metricsSPOff[algorithm]} {
+			for _, suboptimal := range metrics.Suboptimal {
+				if suboptimal {
+					suboptimalRuns++
+				}
+				runs++
+			}
+		}
+
+		admissibility := "admissible"
+		if !algorithms.IsAdmissible(info.Heuristic, cfg.movement.Connectivity) {
+			admissibility = "inadmissible"
+		}
+		flag := ""
+		if suboptimalRuns > 0 {
+			flag = " [SUBOPTIMAL]"
+		}
 		fmt.Printf(
-			"Completed test %d of %d for mazes with multiple paths, for size: %d\n",
-			i+1,
-			numTests,
-			mazeSize,
+			"A* heuristic %s (%s): path cost differs from Dijkstra in %d of %d runs%s\n",
+			info.Heuristic,
+			admissibility,
+			suboptimalRuns,
+			runs,
+			flag,
 		)
-		clearMemory(grids, startNodes, endNodes)
 	}
+}
 
-	// Print the counter at the end
-	fmt.Printf(
-		"Number of times A* path length is different from Dijkstra: %d\n",
-		differentPathCount,
-	)
-
-	averagesSPOn := calculateAverages(metricsSPOn)
-	averagesSPOff := calculateAverages(metricsSPOff)
-
-	if numRows%2 != 0 || numCols%2 != 0 {
-		filename := fmt.Sprintf("%s/averages%dx%dx%d.csv", outputDir, numRows, numCols, numTests)
-		if marker != "" {
-			filename = fmt.Sprintf(
-				"%s/averages%dx%dx%dx%s.csv",
-				outputDir,
-				numRows,
-				numCols,
-				numTests,
-				marker,
-			)
+// countOptimalityViolations prints and counts the runs in which an algorithm
+// that guarantees shortest paths under cfg returned a costlier or an invalid
+// path.
+func countOptimalityViolations(cfg runConfig, metricsSPOn, metricsSPOff map[string]*Metrics) int {
+	violations := 0
+	for _, algorithm := range cfg.algorithms {
+		info, _ := algorithms.Describe(algorithm)
+		if !info.ClaimsOptimal(cfg.movement, cfg.terrain) {
+			continue
 		}
-		writeResultsToCsv(filename, averagesSPOn, averagesSPOff)
-	} else {
-		fmt.Println("Sorry! Even mazes aren't supported, so the size was incremented by 1.")
-		filename := fmt.Sprintf("%s/averages%dx%dx%d.csv", outputDir, numRows+1, numCols+1, numTests)
-		if marker != "" {
-			filename = fmt.Sprintf("%s/averages%dx%dx%dx%s.csv", outputDir, numRows+1, numCols+1, numTests, marker)
+
+		for _, metrics := range []*Metrics{metricsSPOn[algorithm], metricsSPOff[algorithm]} {
+			for i, optimal := range metrics.Optimal {
+				switch {
+				case !metrics.Verified[i]:
+					fmt.Printf("Optimality violation: %s returned an invalid path for seed %d\n", algorithm, metrics.Seeds[i])
+					violations++
+				case !optimal:
+					fmt.Printf("Optimality violation: %s returned a longer path for seed %d\n", algorithm, metrics.Seeds[i])
+					violations++
+				}
+			}
 		}
-		writeResultsToCsv(filename, averagesSPOn, averagesSPOff)
 	}
+	return violations
+}
 
-	return nil
+func initializeMetrics(algorithmNames []string) map[string]*Metrics {
+	metrics := make(map[string]*Metrics)
+	for _, algorithm := range algorithmNames {
+		metrics[algorithm] = &Metrics{}
+	}
+	return metrics
 }
 
-func initializeMetrics() map[string]*Metrics {
-	return map[string]*Metrics{
-		"dijkstra":     {},
-		"astar":        {},
-		"bfs":          {},
-		"dfs":          {},
-		"wallFollower": {},
+// solveMaze runs every configured algorithm on layout, concurrently in
+// parallel mode and one at a time otherwise.
+func solveMaze(cfg runConfig, layout *maze.Layout, metrics map[string]*Metrics) {
+	truth := verify.Solve(layout, cfg.movement)
+
+	if cfg.mode != parallelMode {
+		for j, algorithm := range cfg.algorithms {
+			runAlgorithm(algorithm, cfg, layout, truth, uint16(j+1), metrics)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for j, algorithm := range cfg.algorithms {
+			wg.Add(1)
+			go func(algorithm string) {
+				defer wg.Done()
+				runAlgorithm(algorithm, cfg, layout, truth, uint16(j+1), metrics)
+			}(algorithm)
+		}
+		wg.Wait()
+	}
+
+	mazeStats := layout.Stats()
+	for _, algorithm := range cfg.algorithms {
+		metrics[algorithm].Mazes = append(metrics[algorithm].Mazes, mazeStats)
 	}
 }
 
 func runAlgorithm(
 	algorithm string,
-	grid [][]maze.Node,
-	startNode *maze.Node,
-	endNode *maze.Node,
+	cfg runConfig,
+	layout *maze.Layout,
+	truth verify.GroundTruth,
+	gridId uint16,
 	metrics map[string]*Metrics,
 ) {
-	startTime := time.Now()
-	var initialMemoryUsage runtime.MemStats
-	runtime.ReadMemStats(&initialMemoryUsage)
-
-	visitedNodesInOrder := algorithmsMap[algorithm].FindPath(grid, startNode, endNode)
-
-	var midMemoryUsage runtime.MemStats
-	runtime.ReadMemStats(&midMemoryUsage)
-
-	nodesInShortestPathOrder := getNodesInShortestPathOrder(endNode)
-
-	var finalMemoryUsage runtime.MemStats
-	runtime.ReadMemStats(&finalMemoryUsage)
+	alg, err := algorithms.New(algorithm, algorithms.Options{Movement: cfg.movement})
+	if err != nil {
+		log.Fatalf("Failed to create algorithm: %s", err)
+	}
 
-	endTime := time.Now()
-	timeTaken := endTime.Sub(startTime).Nanoseconds() // Convert to nanoseconds
+	for i := 0; i < cfg.warmup; i++ {
+		grid, startNode, endNode := layout.NewGrid(gridId)
+		alg.FindPath(grid, startNode, endNode)
+	}
 
-	// Check for overflow
-	var memoryUsed float64
-	if finalMemoryUsage.HeapAlloc >= initialMemoryUsage.HeapAlloc {
-		memoryUsed = float64(
-			finalMemoryUsage.HeapAlloc-initialMemoryUsage.HeapAlloc,
-		) / (1024 * 1024) // Convert to MB
-	} else {
-		memoryUsed = 0 // or handle it in another appropriate way
+	grid, startNode, endNode := layout.NewGrid(gridId)
+	var m measurement
+	switch cfg.mode {
+	case isolatedMode:
+		m = measureIsolated(alg, grid, startNode, endNode, cfg.lockThread)
+	case subprocessMode:
+		m, err = measureSubprocess(algorithm, cfg, layout, grid)
+		if err != nil {
+			log.Fatalf("Failed to run algorithm: %s", err)
+		}
+	default:
+		m = measureShared(alg, grid, startNode, endNode)
 	}
+	nodesInShortestPathOrder := m.nodesInShortestPathOrder
 
 	totalNodes := len(grid) * len(grid[0])
 	wallNodes := countWallNodes(grid)
 	nonWallNodes := totalNodes - wallNodes
-	visitedPercentage := (float64(len(visitedNodesInOrder)) / float64(nonWallNodes)) * 100
+	visitedPercentage := (float64(m.visitedNodes) / float64(nonWallNodes)) * 100
 
-	metrics[algorithm].Time = append(metrics[algorithm].Time, float64(timeTaken))
-	metrics[algorithm].VisitedNodes = append(
-		metrics[algorithm].VisitedNodes,
-		len(visitedNodesInOrder),
-	)
+	metrics[algorithm].Time = append(metrics[algorithm].Time, m.time)
+	metrics[algorithm].VisitedNodes = append(metrics[algorithm].VisitedNodes, m.visitedNodes)
 	metrics[algorithm].VisitedPercentage = append(
 		metrics[algorithm].VisitedPercentage,
 		visitedPercentage,
@@ -233,7 +735,23 @@ func runAlgorithm(
 		metrics[algorithm].PathLength,
 		len(nodesInShortestPathOrder),
 	)
-	metrics[algorithm].MemoryUsed = append(metrics[algorithm].MemoryUsed, memoryUsed)
+	metrics[algorithm].PathCost = append(
+		metrics[algorithm].PathCost,
+		algorithms.PathCost(nodesInShortestPathOrder),
+	)
+	metrics[algorithm].MemoryUsed = append(metrics[algorithm].MemoryUsed, m.memoryUsed)
+	metrics[algorithm].Allocs = append(metrics[algorithm].Allocs, m.allocs)
+	metrics[algorithm].WallTime = append(metrics[algorithm].WallTime, m.wallTime)
+	metrics[algorithm].PeakRSS = append(metrics[algorithm].PeakRSS, m.peakRSS)
+	metrics[algorithm].PageFaults = append(metrics[algorithm].PageFaults, m.pageFaults)
+	metrics[algorithm].Seeds = append(metrics[algorithm].Seeds, layout.Seed)
+
+	result := verify.Check(layout, cfg.movement, truth, nodesInShortestPathOrder)
+	if !result.Passed() {
+		fmt.Printf("Verification failed for %s (seed %d): %s\n", algorithm, layout.Seed, result.Err)
+	}
+	metrics[algorithm].Verified = append(metrics[algorithm].Verified, result.Passed())
+	metrics[algorithm].Optimal = append(metrics[algorithm].Optimal, result.Optimal)
 }
 
 func getNodesInShortestPathOrder(endNode *maze.Node) []*maze.Node {
@@ -264,39 +782,31 @@ func countWallNodes(grid [][]maze.Node) int {
 	return count
 }
 
-func calculateAverages(metrics map[string]*Metrics) map[string]map[string]float64 {
-	averages := make(map[string]map[string]float64)
-	for algorithm, metric := range metrics {
-		averages[algorithm] = make(map[string]float64)
-		numTests := len(metric.Time)
-
-		timeSum := 0.0
-		visitedNodesSum := 0
-		visitedPercentageSum := 0.0
-		pathLengthSum := 0
-		memoryUsedSum := 0.0
-
-		for i := 0; i < numTests; i++ {
-			timeSum += metric.Time[i]
-			visitedNodesSum += metric.VisitedNodes[i]
-			visitedPercentageSum += metric.VisitedPercentage[i]
-			pathLengthSum += metric.PathLength[i]
-			memoryUsedSum += metric.MemoryUsed[i]
-		}
-
-		averages[algorithm]["time"] = timeSum / float64(numTests)
-		averages[algorithm]["visitedNodes"] = float64(visitedNodesSum) / float64(numTests)
-		averages[algorithm]["visitedPercentage"] = visitedPercentageSum / float64(numTests)
-		averages[algorithm]["pathLength"] = float64(pathLengthSum / numTests) // Integer division
-		averages[algorithm]["memoryUsed"] = memoryUsedSum / float64(numTests)
-	}
-	return averages
+// processMetric formats a metric that is only measured in subprocess mode.
+func processMetric(cfg runConfig, format string, value float64) string {
+	if cfg.mode != subprocessMode {
+		return "N/A"
+	}
+	return fmt.Sprintf(format, value)
 }
 
-func writeResultsToCsv(filename string, averagesSPOn, averagesSPOff map[string]map[string]float64) {
+// runMetric formats a metric of a single run, which is not reported in
+// throughput mode since the other workers distort it.
+func runMetric(cfg runConfig, format string, value float64) string {
+	if cfg.mode == throughputMode {
+		return "N/A"
+	}
+	return fmt.Sprintf(format, value)
+}
+
+func writeResultsToCsv(
+	filename string,
+	cfg runConfig,
+	summariesSPOn, summariesSPOff map[string]*algorithmSummary,
+) error {
 	file, err := os.Create(filename)
 	if err != nil {
-		log.Fatalf("Failed to create file: %s", err)
+		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 
@@ -311,98 +821,90 @@ func writeResultsToCsv(filename string, averagesSPOn, averagesSPOff map[string]m
 		"VisitedPercentage [%]",
 		"PathLength",
 		"D_PathLength",
+		"PathCost",
+		"SuboptimalRuns",
+		"FailedRuns",
 		"MemoryUsed [MB]",
+		"Allocs",
+		"WallTime [ms]",
+		"PeakRSS [MB]",
+		"PageFaults",
+		"DeadEnds",
+		"Loops",
+		"SolutionFraction",
+		"RiverFactor",
+		"Seed",
+		"Generator",
+		"Terrain",
+		"LoopDensity",
+		"Braid",
+		"AddedLoops",
+		"Connectivity",
+		"Mode",
 	}
+	header = append(header, summaryColumns()...)
 	if err := writer.Write(header); err != nil {
-		log.Fatalf("Failed to write header: %s", err)
+		return fmt.Errorf("failed to write header: %w", err)
 	}
 
-	algorithmOrder := []string{"dijkstra", "astar", "bfs", "dfs", "wallFollower"}
-	dijkstraPathLength := int(averagesSPOff["dijkstra"]["pathLength"])
+	dijkstraSummary, dijkstraExists := summariesSPOff["dijkstra"]
 
-	for _, algorithm := range algorithmOrder {
-		if metrics, exists := averagesSPOn[algorithm]; exists {
-			row := []string{
-				algorithm,
-				"true",
-				fmt.Sprintf("%.2f", metrics["time"]/1e6),
-				fmt.Sprintf("%.0f", metrics["visitedNodes"]),
-				fmt.Sprintf("%.2f", metrics["visitedPercentage"]),
-				fmt.Sprintf("%d", int(metrics["pathLength"])),
-				"N/A",
-				fmt.Sprintf("%.2f", metrics["memoryUsed"]),
+	for _, singlePath := range []bool{true, false} {
+		summaries := summariesSPOff
+		if singlePath {
+			summaries = summariesSPOn
+		}
+		for _, algorithm := range cfg.algorithms {
+			summary, exists := summaries[algorithm]
+			if !exists {
+				continue
 			}
-			if err := writer.Write(row); err != nil {
-				log.Fatalf("Failed to write row for %s: %s", algorithm, err)
+			means := func(key string) float64 {
+				return summary.Metrics[key].Mean
+			}
+			pathLengthDelta := "N/A"
+			if !singlePath && dijkstraExists {
+				pathLengthDelta = fmt.Sprintf("%.2f", means("pathLength")-dijkstraSummary.Metrics["pathLength"].Mean)
 			}
-		}
-	}
-
-	for _, algorithm := range algorithmOrder {
-		if metrics, exists := averagesSPOff[algorithm]; exists {
-			pathLength := int(metrics["pathLength"])
-			pathLengthDelta := pathLength - dijkstraPathLength
 			row := []string{
 				algorithm,
-				"false",
-				fmt.Sprintf("%.2f", metrics["time"]/1e6),
-				fmt.Sprintf("%.0f", metrics["visitedNodes"]),
-				fmt.Sprintf("%.2f", metrics["visitedPercentage"]),
-				fmt.Sprintf("%d", pathLength),
-				fmt.Sprintf("%d", pathLengthDelta),
-				fmt.Sprintf("%.2f", metrics["memoryUsed"]),
+				strconv.FormatBool(singlePath),
+				runMetric(cfg, "%.2f", means("time")),
+				fmt.Sprintf("%.0f", means("visitedNodes")),
+				fmt.Sprintf("%.2f", means("visitedPercentage")),
+				fmt.Sprintf("%.2f", means("pathLength")),
+				pathLengthDelta,
+				fmt.Sprintf("%.2f", means("pathCost")),
+				strconv.Itoa(summary.SuboptimalRuns),
+				strconv.Itoa(summary.FailedRuns),
+				runMetric(cfg, "%.2f", means("memoryUsed")),
+				runMetric(cfg, "%.0f", means("allocs")),
+				processMetric(cfg, "%.2f", means("wallTime")),
+				processMetric(cfg, "%.2f", means("peakRSS")),
+				processMetric(cfg, "%.0f", means("pageFaults")),
+				fmt.Sprintf("%.2f", means("deadEnds")),
+				fmt.Sprintf("%.2f", means("loops")),
+				fmt.Sprintf("%.4f", means("solutionFraction")),
+				fmt.Sprintf("%.2f", means("riverFactor")),
+				strconv.FormatInt(cfg.seed, 10),
+				cfg.generator,
+				strconv.FormatBool(cfg.terrain),
+				strconv.FormatFloat(cfg.loopDensity, 'g', -1, 64),
+				strconv.FormatFloat(cfg.braid, 'g', -1, 64),
+				strconv.Itoa(cfg.loops),
+				strconv.Itoa(int(cfg.movement.Connectivity)),
+				cfg.mode,
 			}
+			row = append(row, summaryRow(cfg, summary)...)
 			if err := writer.Write(row); err != nil {
-				log.Fatalf("Failed to write row for %s: %s", algorithm, err)
+				return fmt.Errorf("failed to write row for %s: %w", algorithm, err)
 			}
 		}
 	}
 
 	writer.Flush()
 	if err := writer.Error(); err != nil {
-		log.Fatalf("Error flushing writer: %s", err)
-	}
-}
-
-func getInitialGrid(
-	numRows, numCols int,
-	singlePath bool,
-) (map[string][][]maze.Node, map[string]*maze.Node, map[string]*maze.Node) {
-	grids := make(map[string][][]maze.Node)
-	startNodes := make(map[string]*maze.Node)
-	endNodes := make(map[string]*maze.Node)
-
-	mazeData := maze.GenerateMaze(numRows, numCols, singlePath)
-
-	grids["dijkstra"] = mazeData["gridDijkstra"].([][]maze.Node)
-	grids["astar"] = mazeData["gridAstar"].([][]maze.Node)
-	grids["bfs"] = mazeData["gridBFS"].([][]maze.Node)
-	grids["dfs"] = mazeData["gridDFS"].([][]maze.Node)
-	grids["wallFollower"] = mazeData["gridWallFollower"].([][]maze.Node)
-
-	startNodes["dijkstra"] = mazeData["gridDijkstraStartNode"].(*maze.Node)
-	startNodes["astar"] = mazeData["gridAstarStartNode"].(*maze.Node)
-	startNodes["bfs"] = mazeData["gridBFSStartNode"].(*maze.Node)
-	startNodes["dfs"] = mazeData["gridDFSStartNode"].(*maze.Node)
-	startNodes["wallFollower"] = mazeData["gridWallFollowerStartNode"].(*maze.Node)
-
-	endNodes["dijkstra"] = mazeData["gridDijkstraEndNode"].(*maze.Node)
-	endNodes["astar"] = mazeData["gridAstarEndNode"].(*maze.Node)
-	endNodes["bfs"] = mazeData["gridBFSEndNode"].(*maze.Node)
-	endNodes["dfs"] = mazeData["gridDFSEndNode"].(*maze.Node)
-	endNodes["wallFollower"] = mazeData["gridWallFollowerEndNode"].(*maze.Node)
-
-	return grids, startNodes, endNodes
-}
-
-func clearMemory(grids map[string][][]maze.Node, startNodes, endNodes map[string]*maze.Node) {
-	for k := range grids {
-		grids[k] = nil
-	}
-	for k := range startNodes {
-		startNodes[k] = nil
-	}
-	for k := range endNodes {
-		endNodes[k] = nil
+		return fmt.Errorf("error flushing writer: %w", err)
 	}
+	return file.Close()
 }
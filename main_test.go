@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestCountOptimalityViolations(t *testing.T) {
+	cfg := runConfig{algorithms: []string{"dijkstra", "dfs"}}
+	runs := func(verified, optimal []bool) *Metrics {
+		return &Metrics{Seeds: make([]int64, len(verified)), Verified: verified, Optimal: optimal}
+	}
+	metricsSPOn := map[string]*Metrics{
+		"dijkstra": runs([]bool{true, true, false}, []bool{true, false, false}),
+		// DFS does not claim shortest paths, so its runs never count.
+		"dfs": runs([]bool{false, true}, []bool{false, false}),
+	}
+	metricsSPOff := map[string]*Metrics{
+		"dijkstra": runs([]bool{false, true}, []bool{true, true}),
+		"dfs":      runs([]bool{true}, []bool{false}),
+	}
+
+	// The longer path, the invalid path and the invalid path that happened
+	// to be short enough.
+	if got := countOptimalityViolations(cfg, metricsSPOn, metricsSPOff); got != 3 {
+		t.Errorf("got %d violations, want 3", got)
+	}
+}
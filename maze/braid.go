@@ -0,0 +1,96 @@
+package maze
+
+import "math"
+
+// degree returns the number of passages carved from a lattice cell.
+func (m *Maze) degree(cell *Cell) int {
+	degree := 0
+	for _, neighbor := range m.latticeNeighbors(cell) {
+		if !m.wallBetween(cell, neighbor).IsWall {
+			degree++
+		}
+	}
+	return degree
+}
+
+// wallBetween returns the wall cell separating two adjacent lattice cells.
+func (m *Maze) wallBetween(a, b *Cell) *Cell {
+	return &m.Grid[(int(a.Y)+int(b.Y))/2][(int(a.X)+int(b.X))/2]
+}
+
+// braid removes round(fraction * dead ends) dead ends of a perfect maze by
+// carving a passage out of each, preferring a neighbouring dead end so that
+// one carve removes two. Every carve adds exactly one loop.
+func (m *Maze) braid(fraction float64) {
+	var deadEnds []*Cell
+	for row := 0; row < m.cellRows(); row++ {
+		for col := 0; col < m.cellCols(); col++ {
+			if cell := m.cell(col, row); m.degree(cell) == 1 {
+				deadEnds = append(deadEnds, cell)
+			}
+		}
+	}
+	m.rng.Shuffle(len(deadEnds), func(i, j int) { deadEnds[i], deadEnds[j] = deadEnds[j], deadEnds[i] })
+	target := int(math.Round(fraction * float64(len(deadEnds))))
+
+	removed := 0
+	// The first pass never overshoots the target; the second one may by a
+	// single dead end when only pairs of dead ends are left to join.
+	for _, exact := range []bool{true, false} {
+		for _, cell := range deadEnds {
+			if removed >= target {
+				return
+			}
+			if m.degree(cell) != 1 {
+				continue
+			}
+
+			var pair, other []*Cell
+			for _, neighbor := range m.latticeNeighbors(cell) {
+				if !m.wallBetween(cell, neighbor).IsWall {
+					continue
+				}
+				if m.degree(neighbor) == 1 {
+					pair = append(pair, neighbor)
+				} else {
+					other = append(other, neighbor)
+				}
+			}
+
+			switch {
+			case len(pair) > 0 && (removed+2 <= target || (!exact && len(other) == 0)):
+				m.carve(cell, pair[m.rng.Intn(len(pair))])
+				removed += 2
+			case len(other) > 0:
+				m.carve(cell, other[m.rng.Intn(len(other))])
+				removed++
+			}
+		}
+	}
+}
+
+// addLoops carves n random walls between lattice cells that are not yet
+// connected directly, each adding one loop, or every such wall if there are
+// fewer.
+func (m *Maze) addLoops(n int) {
+	var walls [][2]*Cell
+	for row := 0; row < m.cellRows(); row++ {
+		for col := 0; col < m.cellCols(); col++ {
+			cell := m.cell(col, row)
+			if col+1 < m.cellCols() {
+				if right := m.cell(col+1, row); m.wallBetween(cell, right).IsWall {
+					walls = append(walls, [2]*Cell{cell, right})
+				}
+			}
+			if row+1 < m.cellRows() {
+				if below := m.cell(col, row+1); m.wallBetween(cell, below).IsWall {
+					walls = append(walls, [2]*Cell{cell, below})
+				}
+			}
+		}
+	}
+	m.rng.Shuffle(len(walls), func(i, j int) { walls[i], walls[j] = walls[j], walls[i] })
+	for _, wall := range walls[:min(n, len(walls))] {
+		m.carve(wall[0], wall[1])
+	}
+}
@@ -0,0 +1,371 @@
+package maze
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Generator carves passages into a freshly initialised Maze, in which every
+// cell at odd coordinates is open and every cell between them is a wall.
+// Generators must draw all randomness from the maze's random source so that
+// the result is reproducible from the seed.
+type Generator interface {
+	Generate(m *Maze)
+}
+
+// DefaultGenerator is the generator used when none is specified.
+const DefaultGenerator = "recursiveBacktracker"
+
+var (
+	generatorsMutex sync.RWMutex
+	generators      = map[string]Generator{}
+	generatorOrder  []string
+)
+
+func init() {
+	RegisterGenerator(DefaultGenerator, RecursiveBacktracker{})
+	RegisterGenerator("prim", Prim{})
+	RegisterGenerator("kruskal", Kruskal{})
+	RegisterGenerator("wilson", Wilson{})
+	RegisterGenerator("eller", Eller{})
+	RegisterGenerator("recursiveDivision", RecursiveDivision{})
+	RegisterGenerator("binaryTree", BinaryTree{})
+	RegisterGenerator("sidewinder", Sidewinder{})
+}
+
+// RegisterGenerator makes a generator available under name. It panics if
+// name is already taken.
+func RegisterGenerator(name string, generator Generator) {
+	generatorsMutex.Lock()
+	defer generatorsMutex.Unlock()
+
+	if _, exists := generators[name]; exists {
+		panic(fmt.Sprintf("maze: generator %q registered twice", name))
+	}
+	generators[name] = generator
+	generatorOrder = append(generatorOrder, name)
+}
+
+// GeneratorNames returns the names of all registered generators in
+// registration order.
+func GeneratorNames() []string {
+	generatorsMutex.RLock()
+	defer generatorsMutex.RUnlock()
+
+	return append([]string(nil), generatorOrder...)
+}
+
+// NewGenerator returns the generator registered under name.
+func NewGenerator(name string) (Generator, error) {
+	generatorsMutex.RLock()
+	defer generatorsMutex.RUnlock()
+
+	generator, exists := generators[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown generator %q", name)
+	}
+	return generator, nil
+}
+
+// cellCols and cellRows return the dimensions of the lattice of open cells.
+func (m *Maze) cellCols() int { return (m.Width - 1) / 2 }
+func (m *Maze) cellRows() int { return (m.Height - 1) / 2 }
+
+// cell returns the open cell at the given lattice position.
+func (m *Maze) cell(col, row int) *Cell {
+	return &m.Grid[2*row+1][2*col+1]
+}
+
+// carve removes the wall between two adjacent lattice cells.
+func (m *Maze) carve(a, b *Cell) {
+	m.Grid[(int(a.Y)+int(b.Y))/2][(int(a.X)+int(b.X))/2].IsWall = false
+}
+
+// latticeNeighbors returns the lattice cells adjacent to cell, visited or not.
+func (m *Maze) latticeNeighbors(cell *Cell) []*Cell {
+	neighbors := make([]*Cell, 0, 4)
+	for _, d := range [4][2]int{{0, -2}, {2, 0}, {0, 2}, {-2, 0}} {
+		if neighbor := m.getCell(int(cell.X)+d[0], int(cell.Y)+d[1]); neighbor != nil {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors
+}
+
+// RecursiveBacktracker is the randomised depth-first carver with an
+// occasional jump back to a random cell on the stack.
+type RecursiveBacktracker struct{}
+
+func (RecursiveBacktracker) Generate(m *Maze) {
+	for len(m.Stack) > 0 || !m.CurrentCell.Visited {
+		m.generateMazeNotGlobal()
+	}
+}
+
+// Prim grows the maze from a single cell by repeatedly connecting a random
+// frontier cell to the visited region.
+type Prim struct{}
+
+func (Prim) Generate(m *Maze) {
+	start := m.cell(m.rng.Intn(m.cellCols()), m.rng.Intn(m.cellRows()))
+	start.Visited = true
+
+	inFrontier := make(map[*Cell]bool)
+	var frontier []*Cell
+	addFrontier := func(cell *Cell) {
+		for _, neighbor := range m.getNeighbors(cell) {
+			if !inFrontier[neighbor] {
+				inFrontier[neighbor] = true
+				frontier = append(frontier, neighbor)
+			}
+		}
+	}
+	addFrontier(start)
+
+	for len(frontier) > 0 {
+		i := m.rng.Intn(len(frontier))
+		cell := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		var visited []*Cell
+		for _, neighbor := range m.latticeNeighbors(cell) {
+			if neighbor.Visited {
+				visited = append(visited, neighbor)
+			}
+		}
+		m.carve(cell, visited[m.rng.Intn(len(visited))])
+		cell.Visited = true
+		addFrontier(cell)
+	}
+}
+
+// Kruskal removes walls in random order whenever they separate two
+// not yet connected regions.
+type Kruskal struct{}
+
+func (Kruskal) Generate(m *Maze) {
+	cols, rows := m.cellCols(), m.cellRows()
+	parent := make([]int, cols*rows)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	type edge struct{ a, b int }
+	edges := make([]edge, 0, 2*cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if col+1 < cols {
+				edges = append(edges, edge{row*cols + col, row*cols + col + 1})
+			}
+			if row+1 < rows {
+				edges = append(edges, edge{row*cols + col, (row+1)*cols + col})
+			}
+		}
+	}
+	m.rng.Shuffle(len(edges), func(i, j int) { edges[i], edges[j] = edges[j], edges[i] })
+
+	for _, e := range edges {
+		rootA, rootB := find(e.a), find(e.b)
+		if rootA == rootB {
+			continue
+		}
+		parent[rootA] = rootB
+		m.carve(m.cell(e.a%cols, e.a/cols), m.cell(e.b%cols, e.b/cols))
+	}
+}
+
+// Wilson builds a uniform spanning tree from loop-erased random walks.
+type Wilson struct{}
+
+func (Wilson) Generate(m *Maze) {
+	cols, rows := m.cellCols(), m.cellRows()
+	remaining := cols*rows - 1
+	m.cell(m.rng.Intn(cols), m.rng.Intn(rows)).Visited = true
+
+	next := make(map[*Cell]*Cell)
+	for remaining > 0 {
+		start := m.cell(m.rng.Intn(cols), m.rng.Intn(rows))
+		if start.Visited {
+			continue
+		}
+
+		// Walk until the tree is hit; overwriting next erases loops.
+		for cell := start; !cell.Visited; {
+			neighbors := m.latticeNeighbors(cell)
+			next[cell] = neighbors[m.rng.Intn(len(neighbors))]
+			cell = next[cell]
+		}
+
+		for cell := start; !cell.Visited; cell = next[cell] {
+			cell.Visited = true
+			m.carve(cell, next[cell])
+			remaining--
+		}
+		clear(next)
+	}
+}
+
+// Eller generates the maze one row at a time, tracking which cells of the
+// current row are already connected.
+type Eller struct{}
+
+func (Eller) Generate(m *Maze) {
+	cols, rows := m.cellCols(), m.cellRows()
+	sets := make([]int, cols)
+	nextSet := 1
+
+	for row := 0; row < rows; row++ {
+		lastRow := row == rows-1
+		for col := range sets {
+			if sets[col] == 0 {
+				sets[col] = nextSet
+				nextSet++
+			}
+		}
+
+		// Join horizontally adjacent cells from different sets.
+		for col := 0; col+1 < cols; col++ {
+			if sets[col] == sets[col+1] || (!lastRow && m.rng.Intn(2) == 0) {
+				continue
+			}
+			m.carve(m.cell(col, row), m.cell(col+1, row))
+			from, to := sets[col+1], sets[col]
+			for i := range sets {
+				if sets[i] == from {
+					sets[i] = to
+				}
+			}
+		}
+		if lastRow {
+			break
+		}
+
+		// Every set extends downwards at least once.
+		members := make(map[int][]int)
+		var order []int
+		for col, set := range sets {
+			if _, exists := members[set]; !exists {
+				order = append(order, set)
+			}
+			members[set] = append(members[set], col)
+		}
+		nextSets := make([]int, cols)
+		for _, set := range order {
+			cells := members[set]
+			m.rng.Shuffle(len(cells), func(i, j int) { cells[i], cells[j] = cells[j], cells[i] })
+			for i, col := range cells {
+				if i > 0 && m.rng.Intn(2) == 0 {
+					continue
+				}
+				m.carve(m.cell(col, row), m.cell(col, row+1))
+				nextSets[col] = set
+			}
+		}
+		sets = nextSets
+	}
+}
+
+// RecursiveDivision starts from an open field and recursively splits it
+// with walls that each have a single gap.
+type RecursiveDivision struct{}
+
+func (RecursiveDivision) Generate(m *Maze) {
+	cols, rows := m.cellCols(), m.cellRows()
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if col+1 < cols {
+				m.carve(m.cell(col, row), m.cell(col+1, row))
+			}
+			if row+1 < rows {
+				m.carve(m.cell(col, row), m.cell(col, row+1))
+			}
+		}
+	}
+	m.divide(0, 0, cols, rows)
+}
+
+// divide splits the lattice region of w x h cells at (col, row).
+func (m *Maze) divide(col, row, w, h int) {
+	if w < 2 || h < 2 {
+		return
+	}
+
+	horizontal := h > w || (h == w && m.rng.Intn(2) == 0)
+	if horizontal {
+		split := row + m.rng.Intn(h-1) // wall below this lattice row
+		gap := col + m.rng.Intn(w)
+		y := 2*split + 2
+		for c := col; c < col+w; c++ {
+			if c != gap {
+				m.Grid[y][2*c+1].IsWall = true
+			}
+		}
+		m.divide(col, row, w, split-row+1)
+		m.divide(col, split+1, w, row+h-split-1)
+	} else {
+		split := col + m.rng.Intn(w-1) // wall right of this lattice column
+		gap := row + m.rng.Intn(h)
+		x := 2*split + 2
+		for r := row; r < row+h; r++ {
+			if r != gap {
+				m.Grid[2*r+1][x].IsWall = true
+			}
+		}
+		m.divide(col, row, split-col+1, h)
+		m.divide(split+1, row, col+w-split-1, h)
+	}
+}
+
+// BinaryTree links every cell to either its northern or western neighbour.
+type BinaryTree struct{}
+
+func (BinaryTree) Generate(m *Maze) {
+	for row := 0; row < m.cellRows(); row++ {
+		for col := 0; col < m.cellCols(); col++ {
+			switch {
+			case row > 0 && col > 0:
+				if m.rng.Intn(2) == 0 {
+					m.carve(m.cell(col, row), m.cell(col, row-1))
+				} else {
+					m.carve(m.cell(col, row), m.cell(col-1, row))
+				}
+			case row > 0:
+				m.carve(m.cell(col, row), m.cell(col, row-1))
+			case col > 0:
+				m.carve(m.cell(col, row), m.cell(col-1, row))
+			}
+		}
+	}
+}
+
+// Sidewinder carves eastward runs and closes each run with a single
+// passage north.
+type Sidewinder struct{}
+
+func (Sidewinder) Generate(m *Maze) {
+	cols := m.cellCols()
+	for row := 0; row < m.cellRows(); row++ {
+		runStart := 0
+		for col := 0; col < cols; col++ {
+			closeRun := col == cols-1 || (row > 0 && m.rng.Intn(2) == 0)
+			if !closeRun {
+				m.carve(m.cell(col, row), m.cell(col+1, row))
+				continue
+			}
+			if row > 0 {
+				chosen := runStart + m.rng.Intn(col-runStart+1)
+				m.carve(m.cell(chosen, row), m.cell(chosen, row-1))
+			}
+			runStart = col + 1
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package maze
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Layout is the static description of a generated maze: its dimensions,
+// walls and endpoints. It holds no search state, so any number of
+// algorithms can materialise their own grid from it with NewGrid.
+type Layout struct {
+	Width, Height int
+	Walls         [][]bool
+	Start         Cell
+	End           Cell
+	SinglePath    bool
+	Seed          int64
+	Generator     string
+	// Costs holds the movement cost of entering each cell; nil means every
+	// cell costs CostRoad.
+	Costs [][]uint8
+}
+
+// Layout captures the current walls of m as a Layout.
+func (m *Maze) Layout(seed int64) *Layout {
+	walls := make([][]bool, m.Height)
+	for y, row := range m.Grid {
+		walls[y] = make([]bool, m.Width)
+		for x, cell := range row {
+			walls[y][x] = cell.IsWall
+		}
+	}
+
+	return &Layout{
+		Width:  m.Width,
+		Height: m.Height,
+		Walls:  walls,
+		Start:  Cell{X: m.Start.X, Y: m.Start.Y},
+		End:    Cell{X: m.End.X, Y: m.End.Y},
+		Seed:   seed,
+	}
+}
+
+// NewGrid materialises a fresh search grid for the layout and returns it
+// together with its start and end nodes.
+func (l *Layout) NewGrid(gridId uint16) ([][]Node, *Node, *Node) {
+	grid := make([][]Node, l.Height)
+	for y := 0; y < l.Height; y++ {
+		grid[y] = make([]Node, l.Width)
+		for x := 0; x < l.Width; x++ {
+			grid[y][x] = createNode(uint16(x), uint16(y), l.Walls[y][x], l.Cost(x, y), &l.Start, &l.End, gridId)
+		}
+	}
+
+	return grid, &grid[l.Start.Y][l.Start.X], &grid[l.End.Y][l.End.X]
+}
+
+// Cost returns the movement cost of entering the cell at (x, y).
+func (l *Layout) Cost(x, y int) uint8 {
+	if l.Costs == nil {
+		return CostRoad
+	}
+	return l.Costs[y][x]
+}
+
+// Characters of the text format of a layout.
+const (
+	textWall  = '#'
+	textOpen  = '.'
+	textStart = 'S'
+	textEnd   = 'E'
+)
+
+// WriteText writes the walls and endpoints of the layout, one line per row,
+// with '#' for walls, '.' for open cells and 'S' and 'E' for the start and
+// end. Costs are not written.
+func (l *Layout) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for y := 0; y < l.Height; y++ {
+		for x := 0; x < l.Width; x++ {
+			c := byte(textOpen)
+			switch {
+			case x == int(l.Start.X) && y == int(l.Start.Y):
+				c = textStart
+			case x == int(l.End.X) && y == int(l.End.Y):
+				c = textEnd
+			case l.Walls[y][x]:
+				c = textWall
+			}
+			bw.WriteByte(c)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ReadText reads a layout in the format of WriteText. Every character other
+// than '#', 'S' and 'E' is an open cell.
+func ReadText(r io.Reader) (*Layout, error) {
+	l := &Layout{}
+	start, end := false, false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if l.Width == 0 {
+			l.Width = len(line)
+		}
+		if len(line) != l.Width {
+			return nil, fmt.Errorf("line %d has %d cells, want %d", l.Height+1, len(line), l.Width)
+		}
+		walls := make([]bool, l.Width)
+		for x := 0; x < len(line); x++ {
+			switch line[x] {
+			case textWall:
+				walls[x] = true
+			case textStart:
+				l.Start, start = Cell{X: uint16(x), Y: uint16(l.Height)}, true
+			case textEnd:
+				l.End, end = Cell{X: uint16(x), Y: uint16(l.Height)}, true
+			}
+		}
+		l.Walls = append(l.Walls, walls)
+		l.Height++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if l.Height == 0 {
+		return nil, fmt.Errorf("empty maze")
+	}
+	if !start || !end {
+		return nil, fmt.Errorf("maze needs a start 'S' and an end 'E'")
+	}
+	return l, nil
+}
@@ -3,7 +3,6 @@ package maze
 import (
 	"math"
 	"math/rand"
-	"time"
 )
 
 type Cell struct {
@@ -21,10 +20,11 @@ type Node struct {
 	IsVisited    bool    `json:"isVisited"`
 	IsWall       bool    `json:"isWall"`
 	PreviousNode *Node   `json:"previousNode"`
-	GridId       uint8   `json:"gridId"`
+	GridId       uint16  `json:"gridId"`
 	NoOfVisits   uint8   `json:"noOfVisits"`
 	F            float32 `json:"f"`
 	G            float32 `json:"g"`
+	Cost         uint8   `json:"cost"`
 }
 
 type Maze struct {
@@ -34,9 +34,10 @@ type Maze struct {
 	CurrentCell   *Cell
 	Start         *Cell
 	End           *Cell
+	rng           *rand.Rand
 }
 
-func NewMaze(width, height int) *Maze {
+func NewMaze(width, height int, r *rand.Rand) *Maze {
 	// Increase dimensions by 1 if they're even
 	if width%2 == 0 {
 		width++
@@ -50,6 +51,7 @@ func NewMaze(width, height int) *Maze {
 		Height: height,
 		Grid:   make([][]Cell, height),
 		Stack:  make([]*Cell, 0, (width*height)/2), // Preallocate stack with estimated capacity
+		rng:    r,
 	}
 
 	// Initialize the grid with walls and paths
@@ -118,7 +120,7 @@ func (m *Maze) generateMazeNotGlobal() {
 	nextCell := m.getNeighbors(m.CurrentCell)
 
 	if nextCell != nil {
-		nextCellCell := nextCell[rand.Intn(len(nextCell))] // Choose a random neighbor
+		nextCellCell := nextCell[m.rng.Intn(len(nextCell))] // Choose a random neighbor
 		nextCellCell.Visited = true
 		m.Stack = append(m.Stack, m.CurrentCell)
 
@@ -132,8 +134,8 @@ func (m *Maze) generateMazeNotGlobal() {
 
 		m.CurrentCell = nextCellCell
 	} else if len(m.Stack) > 0 {
-		if rand.Float64() < 0.2 {
-			backtrackCell := m.Stack[rand.Intn(len(m.Stack))]
+		if m.rng.Float64() < 0.2 {
+			backtrackCell := m.Stack[m.rng.Intn(len(m.Stack))]
 			m.CurrentCell = backtrackCell
 		} else {
 			m.CurrentCell = m.Stack[len(m.Stack)-1]
@@ -142,7 +144,7 @@ func (m *Maze) generateMazeNotGlobal() {
 	}
 }
 
-func createNode(x, y uint16, isWall bool, start, end *Cell, gridId uint8) Node {
+func createNode(x, y uint16, isWall bool, cost uint8, start, end *Cell, gridId uint16) Node {
 	return Node{
 		X:            x,
 		Y:            y,
@@ -153,22 +155,79 @@ func createNode(x, y uint16, isWall bool, start, end *Cell, gridId uint8) Node {
 		IsWall:       isWall,
 		PreviousNode: nil,
 		GridId:       gridId,
+		Cost:         cost,
 	}
 }
 
-func GenerateMaze(numRows, numCols int, singlePath bool) map[string]interface{} {
-	s := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(s)
-	maze := NewMaze(numCols, numRows) // Note: numCols is width, numRows is height
+// DeriveSeed returns the seed of the index-th maze generated from master, so
+// that every maze of a run can be regenerated from the master seed alone.
+func DeriveSeed(master int64, index int) int64 {
+	// splitmix64 finalizer
+	z := uint64(master) + uint64(index+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Options configures Generate.
+type Options struct {
+	Rows, Cols int
+	SinglePath bool
+	Seed       int64
+	// Generator names a registered Generator; DefaultGenerator if empty.
+	Generator string
+	// Terrain assigns noise-based movement costs to the open cells.
+	Terrain bool
+	// LoopDensity is the fraction of the cells that is knocked open to
+	// create extra paths when SinglePath is false; DefaultLoopDensity if
+	// zero.
+	LoopDensity float64
+	// Braid is the fraction of the dead ends removed, and Loops the number
+	// of loops added after braiding, when SinglePath is false. Either one
+	// replaces the random cells opened by LoopDensity.
+	Braid float64
+	Loops int
+}
+
+// DefaultLoopDensity is the loop density used when none is specified.
+const DefaultLoopDensity = 0.1
 
-	// Generate maze with the current implementation
-	for len(maze.Stack) > 0 || !maze.CurrentCell.Visited {
-		maze.generateMazeNotGlobal()
+// GenerateMaze builds a maze from seed with the default generator; the same
+// arguments always produce the same maze.
+func GenerateMaze(numRows, numCols int, singlePath bool, seed int64) *Layout {
+	layout, _ := Generate(Options{Rows: numRows, Cols: numCols, SinglePath: singlePath, Seed: seed})
+	return layout
+}
+
+// Generate builds a maze as described by opts; the same options always
+// produce the same maze.
+func Generate(opts Options) (*Layout, error) {
+	if opts.Generator == "" {
+		opts.Generator = DefaultGenerator
+	}
+	generator, err := NewGenerator(opts.Generator)
+	if err != nil {
+		return nil, err
 	}
 
+	numRows, numCols := opts.Rows, opts.Cols
+	r := rand.New(rand.NewSource(opts.Seed))
+	maze := NewMaze(numCols, numRows, r) // Note: numCols is width, numRows is height
+
+	generator.Generate(maze)
+
 	// Add extra paths if not single path
-	if !singlePath {
-		for i := 0; i < numRows*numCols/10; i++ {
+	switch {
+	case opts.SinglePath:
+	case opts.Braid > 0 || opts.Loops > 0:
+		maze.braid(opts.Braid)
+		maze.addLoops(opts.Loops)
+	default:
+		density := opts.LoopDensity
+		if density == 0 {
+			density = DefaultLoopDensity
+		}
+		for i := 0; i < int(float64(numRows*numCols)*density); i++ {
 			x := r.Intn(numCols)
 			y := r.Intn(numRows)
 			if x > 0 && x < numCols-1 && y > 0 && y < numRows-1 {
@@ -177,35 +236,11 @@ func GenerateMaze(numRows, numCols int, singlePath bool) map[string]interface{}
 		}
 	}
 
-	// Generate grids for different algorithms
-	grids := make(map[int][][]Node, 5)
-	for i := 1; i <= 5; i++ {
-		grid := make([][]Node, len(maze.Grid))
-		for y, row := range maze.Grid {
-			grid[y] = make([]Node, len(row))
-			for x, cell := range row {
-				grid[y][x] = createNode(cell.X, cell.Y, cell.IsWall, maze.Start, maze.End, uint8(i))
-			}
-		}
-		grids[i] = grid
-	}
-
-	// Return maze data for different algorithms
-	return map[string]interface{}{
-		"gridDijkstra":              grids[1],
-		"gridAstar":                 grids[2],
-		"gridBFS":                   grids[3],
-		"gridDFS":                   grids[4],
-		"gridWallFollower":          grids[5],
-		"gridDijkstraStartNode":     &grids[1][maze.Start.Y][maze.Start.X],
-		"gridDijkstraEndNode":       &grids[1][maze.End.Y][maze.End.X],
-		"gridAstarStartNode":        &grids[2][maze.Start.Y][maze.Start.X],
-		"gridAstarEndNode":          &grids[2][maze.End.Y][maze.End.X],
-		"gridBFSStartNode":          &grids[3][maze.Start.Y][maze.Start.X],
-		"gridBFSEndNode":            &grids[3][maze.End.Y][maze.End.X],
-		"gridDFSStartNode":          &grids[4][maze.Start.Y][maze.Start.X],
-		"gridDFSEndNode":            &grids[4][maze.End.Y][maze.End.X],
-		"gridWallFollowerStartNode": &grids[5][maze.Start.Y][maze.Start.X],
-		"gridWallFollowerEndNode":   &grids[5][maze.End.Y][maze.End.X],
+	layout := maze.Layout(opts.Seed)
+	layout.SinglePath = opts.SinglePath
+	layout.Generator = opts.Generator
+	if opts.Terrain {
+		layout.Costs = generateTerrain(layout.Width, layout.Height, r)
 	}
+	return layout, nil
 }
@@ -0,0 +1,273 @@
+package maze
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// topology counts the open cells and the edges between orthogonally
+// adjacent open cells of layout, and the open cells reachable from start.
+func topology(layout *Layout) (open, edges, reachable int) {
+	for y := 0; y < layout.Height; y++ {
+		for x := 0; x < layout.Width; x++ {
+			if layout.Walls[y][x] {
+				continue
+			}
+			open++
+			if x+1 < layout.Width && !layout.Walls[y][x+1] {
+				edges++
+			}
+			if y+1 < layout.Height && !layout.Walls[y+1][x] {
+				edges++
+			}
+		}
+	}
+
+	seen := make([][]bool, layout.Height)
+	for y := range seen {
+		seen[y] = make([]bool, layout.Width)
+	}
+	queue := []Cell{layout.Start}
+	seen[layout.Start.Y][layout.Start.X] = true
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		reachable++
+		for _, d := range [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			x, y := int(cell.X)+d[0], int(cell.Y)+d[1]
+			if x < 0 || y < 0 || x >= layout.Width || y >= layout.Height || layout.Walls[y][x] || seen[y][x] {
+				continue
+			}
+			seen[y][x] = true
+			queue = append(queue, Cell{X: uint16(x), Y: uint16(y)})
+		}
+	}
+
+	return open, edges, reachable
+}
+
+func TestGenerateSinglePathIsPerfect(t *testing.T) {
+	sizes := [][2]int{{3, 3}, {5, 11}, {11, 5}, {21, 21}, {50, 50}, {101, 51}}
+
+	for _, generator := range GeneratorNames() {
+		t.Run(generator, func(t *testing.T) {
+			for _, size := range sizes {
+				for seed := int64(0); seed < 10; seed++ {
+					layout, err := Generate(Options{
+						Rows:       size[0],
+						Cols:       size[1],
+						SinglePath: true,
+						Seed:       seed,
+						Generator:  generator,
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					open, edges, reachable := topology(layout)
+					if reachable != open {
+						t.Errorf("%dx%d seed %d: %d of %d open cells reachable", size[0], size[1], seed, reachable, open)
+					}
+					// A connected graph is a tree exactly when it has one
+					// edge fewer than vertices.
+					if edges != open-1 {
+						t.Errorf("%dx%d seed %d: %d edges for %d open cells, maze has cycles", size[0], size[1], seed, edges, open)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateMazeIsPerfect(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		layout := GenerateMaze(31, 31, true, seed)
+		open, edges, reachable := topology(layout)
+		if reachable != open || edges != open-1 {
+			t.Errorf("seed %d: open %d, edges %d, reachable %d", seed, open, edges, reachable)
+		}
+	}
+}
+
+func TestGenerateMultiplePathsHasLoops(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		// Knocked out pillars may be isolated, so only loops are checked.
+		layout := GenerateMaze(31, 31, false, seed)
+		open, edges, _ := topology(layout)
+		if edges < open {
+			t.Errorf("seed %d: %d edges for %d open cells, expected loops", seed, edges, open)
+		}
+	}
+}
+
+func TestGenerateLoopDensity(t *testing.T) {
+	opts := Options{Rows: 51, Cols: 51, Seed: 7}
+	defaultLayout, _ := Generate(opts)
+	opts.LoopDensity = DefaultLoopDensity
+	explicit, _ := Generate(opts)
+	if !reflect.DeepEqual(defaultLayout.Walls, explicit.Walls) {
+		t.Error("the default loop density differs from an explicit one")
+	}
+
+	previous := 0
+	for _, density := range []float64{0.05, 0.1, 0.3} {
+		opts.LoopDensity = density
+		layout, _ := Generate(opts)
+		open, _, _ := topology(layout)
+		if open <= previous {
+			t.Errorf("density %g: %d open cells, not more than %d at a lower density", density, open, previous)
+		}
+		previous = open
+	}
+}
+
+func TestGenerateBraid(t *testing.T) {
+	for _, generator := range GeneratorNames() {
+		perfect, _ := Generate(Options{Rows: 61, Cols: 61, Seed: 3, Generator: generator, SinglePath: true})
+		before := perfect.Topology()
+		if before.Loops != 0 {
+			t.Fatalf("%s: perfect maze has %d loops", generator, before.Loops)
+		}
+
+		for _, braid := range []float64{0.25, 0.5, 1} {
+			layout, _ := Generate(Options{Rows: 61, Cols: 61, Seed: 3, Generator: generator, Braid: braid})
+			after := layout.Topology()
+			want := before.DeadEnds - int(math.Round(braid*float64(before.DeadEnds)))
+			// Joining two dead ends may overshoot the target by one.
+			if after.DeadEnds != want && after.DeadEnds != want-1 {
+				t.Errorf("%s, braid %g: %d dead ends, want %d of %d", generator, braid, after.DeadEnds, want, before.DeadEnds)
+			}
+			if after.Loops == 0 {
+				t.Errorf("%s, braid %g: no loops", generator, braid)
+			}
+		}
+	}
+}
+
+func TestGenerateLoops(t *testing.T) {
+	for _, generator := range GeneratorNames() {
+		layout, _ := Generate(Options{Rows: 41, Cols: 41, Seed: 5, Generator: generator, Loops: 17})
+		if loops := layout.Topology().Loops; loops != 17 {
+			t.Errorf("%s: %d loops, want 17", generator, loops)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	for _, generator := range GeneratorNames() {
+		opts := Options{Rows: 41, Cols: 41, Seed: 42, Generator: generator, Terrain: true}
+		first, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, _ := Generate(opts)
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("%s: same options produced different mazes", generator)
+		}
+
+		opts.Seed++
+		third, _ := Generate(opts)
+		if reflect.DeepEqual(first.Walls, third.Walls) {
+			t.Errorf("%s: different seeds produced the same maze", generator)
+		}
+	}
+}
+
+func TestGenerateUnknownGenerator(t *testing.T) {
+	if _, err := Generate(Options{Rows: 11, Cols: 11, Generator: "nope"}); err == nil {
+		t.Error("Generate succeeded with an unknown generator")
+	}
+}
+
+func TestNewGrid(t *testing.T) {
+	layout, err := Generate(Options{Rows: 11, Cols: 15, Seed: 1, Terrain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grid, startNode, endNode := layout.NewGrid(3)
+	if len(grid) != layout.Height || len(grid[0]) != layout.Width {
+		t.Fatalf("grid is %dx%d, want %dx%d", len(grid), len(grid[0]), layout.Height, layout.Width)
+	}
+	if !startNode.IsStart || startNode.X != layout.Start.X || startNode.Y != layout.Start.Y {
+		t.Errorf("start node = %+v, want %+v", *startNode, layout.Start)
+	}
+	if !endNode.IsEnd || endNode.X != layout.End.X || endNode.Y != layout.End.Y {
+		t.Errorf("end node = %+v, want %+v", *endNode, layout.End)
+	}
+	for y, row := range grid {
+		for x, node := range row {
+			if node.IsWall != layout.Walls[y][x] || node.Cost != layout.Costs[y][x] || node.GridId != 3 {
+				t.Fatalf("node (%d,%d) = %+v does not match layout", x, y, node)
+			}
+			if node.Cost < CostRoad {
+				t.Fatalf("node (%d,%d) has cost %d", x, y, node.Cost)
+			}
+		}
+	}
+
+	// Grids are independent of each other.
+	other, _, _ := layout.NewGrid(4)
+	grid[startNode.Y][startNode.X].IsVisited = true
+	if other[startNode.Y][startNode.X].IsVisited {
+		t.Error("grids share nodes")
+	}
+}
+
+func TestLayoutStats(t *testing.T) {
+	tests := []struct {
+		text string
+		want Stats
+	}{
+		{
+			text: "#####\n#S..#\n#.#.#\n#..E#\n#####\n",
+			want: Stats{Loops: 1, MeanCorridor: 8, MaxCorridor: 8, SolutionLength: 5, SolutionFraction: 5.0 / 8},
+		},
+		{
+			text: "#######\n#S...E#\n###.###\n###.###\n#######\n",
+			want: Stats{
+				DeadEnds: 3, Junctions: 1, MeanCorridor: 1, MaxCorridor: 1,
+				SolutionLength: 5, SolutionFraction: 5.0 / 7, RiverFactor: 2.0 / 3,
+			},
+		},
+		{
+			text: "#####\n#S#E#\n#####\n",
+			want: Stats{},
+		},
+	}
+	for _, test := range tests {
+		layout, err := ReadText(strings.NewReader(test.text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := layout.Stats(); got != test.want {
+			t.Errorf("%q: got %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	layout, _ := Generate(Options{Rows: 21, Cols: 31, Seed: 9, Braid: 0.5})
+	var b strings.Builder
+	if err := layout.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadText(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Walls, layout.Walls) || read.Start != layout.Start || read.End != layout.End {
+		t.Error("the layout read back differs from the one written")
+	}
+	if read.Stats() != layout.Stats() {
+		t.Error("the stats of the layout read back differ")
+	}
+
+	for _, broken := range []string{"", "#S#\n#E\n", "#S.#\n"} {
+		if _, err := ReadText(strings.NewReader(broken)); err == nil {
+			t.Errorf("ReadText accepted %q", broken)
+		}
+	}
+}
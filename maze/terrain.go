@@ -0,0 +1,70 @@
+package maze
+
+import "math/rand"
+
+// Movement costs of the terrain types. Every cost is at least CostRoad, so
+// distance heuristics measured in steps stay admissible on weighted grids.
+const (
+	CostRoad  uint8 = 1
+	CostMud   uint8 = 3
+	CostWater uint8 = 5
+)
+
+// terrainScale is the spacing, in cells, of the value noise lattice.
+const terrainScale = 8
+
+// generateTerrain assigns a cost to every cell from two octaves of value
+// noise, so that terrain types form contiguous patches.
+func generateTerrain(width, height int, r *rand.Rand) [][]uint8 {
+	coarse := newValueNoise(width, height, terrainScale, r)
+	fine := newValueNoise(width, height, terrainScale/2, r)
+
+	costs := make([][]uint8, height)
+	for y := 0; y < height; y++ {
+		costs[y] = make([]uint8, width)
+		for x := 0; x < width; x++ {
+			v := (2*coarse.at(x, y) + fine.at(x, y)) / 3
+			switch {
+			case v < 0.5:
+				costs[y][x] = CostRoad
+			case v < 0.7:
+				costs[y][x] = CostMud
+			default:
+				costs[y][x] = CostWater
+			}
+		}
+	}
+	return costs
+}
+
+// valueNoise holds random values on a lattice with the given spacing and
+// interpolates between them.
+type valueNoise struct {
+	scale  int
+	values [][]float64
+}
+
+func newValueNoise(width, height, scale int, r *rand.Rand) valueNoise {
+	values := make([][]float64, height/scale+2)
+	for y := range values {
+		values[y] = make([]float64, width/scale+2)
+		for x := range values[y] {
+			values[y][x] = r.Float64()
+		}
+	}
+	return valueNoise{scale: scale, values: values}
+}
+
+func (n valueNoise) at(x, y int) float64 {
+	x0, y0 := x/n.scale, y/n.scale
+	tx := smoothstep(float64(x%n.scale) / float64(n.scale))
+	ty := smoothstep(float64(y%n.scale) / float64(n.scale))
+
+	top := lerp(n.values[y0][x0], n.values[y0][x0+1], tx)
+	bottom := lerp(n.values[y0+1][x0], n.values[y0+1][x0+1], tx)
+	return lerp(top, bottom, ty)
+}
+
+func lerp(a, b, t float64) float64 { return a + (b-a)*t }
+
+func smoothstep(t float64) float64 { return t * t * (3 - 2*t) }
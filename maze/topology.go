@@ -0,0 +1,195 @@
+package maze
+
+// Topology describes the open cells of a layout as a graph whose edges join
+// orthogonally adjacent open cells.
+type Topology struct {
+	// DeadEnds counts the open cells with exactly one open neighbour.
+	DeadEnds int
+	// Junctions counts the open cells with three or more open neighbours.
+	Junctions int
+	// Loops is the number of independent cycles, edges - cells + components.
+	Loops int
+	// Corridors holds the length in cells of every corridor, a maximal
+	// chain of open cells with exactly two open neighbours.
+	Corridors []int
+	// OpenCells counts the cells that are not walls.
+	OpenCells int
+}
+
+// Topology measures the structure of the layout.
+func (l *Layout) Topology() Topology {
+	var t Topology
+	edges, components := 0, 0
+	degrees := make([][]uint8, l.Height)
+	for y := range degrees {
+		degrees[y] = make([]uint8, l.Width)
+	}
+
+	var buf [4]Cell
+	for y := 0; y < l.Height; y++ {
+		for x := 0; x < l.Width; x++ {
+			if l.Walls[y][x] {
+				continue
+			}
+			t.OpenCells++
+			neighbors := l.openNeighbors(x, y, &buf)
+			for _, n := range neighbors {
+				// Every edge is seen from both ends.
+				if n.Y > uint16(y) || (n.Y == uint16(y) && n.X > uint16(x)) {
+					edges++
+				}
+			}
+			degrees[y][x] = uint8(len(neighbors))
+			switch {
+			case len(neighbors) == 1:
+				t.DeadEnds++
+			case len(neighbors) >= 3:
+				t.Junctions++
+			}
+		}
+	}
+
+	// Flood fills count the components over all open cells and the
+	// corridors over the cells of degree two.
+	seen := make([][]bool, l.Height)
+	for y := range seen {
+		seen[y] = make([]bool, l.Width)
+	}
+	inCorridor := make([][]bool, l.Height)
+	for y := range inCorridor {
+		inCorridor[y] = make([]bool, l.Width)
+	}
+	var stack []Cell
+	fill := func(x, y int, visited [][]bool, include func(n Cell) bool) int {
+		size := 0
+		visited[y][x] = true
+		stack = append(stack[:0], Cell{X: uint16(x), Y: uint16(y)})
+		for len(stack) > 0 {
+			cell := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for _, n := range l.openNeighbors(int(cell.X), int(cell.Y), &buf) {
+				if !visited[n.Y][n.X] && include(n) {
+					visited[n.Y][n.X] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+		return size
+	}
+	isCorridor := func(n Cell) bool { return degrees[n.Y][n.X] == 2 }
+	anyOpen := func(Cell) bool { return true }
+
+	for y := 0; y < l.Height; y++ {
+		for x := 0; x < l.Width; x++ {
+			if l.Walls[y][x] {
+				continue
+			}
+			if !seen[y][x] {
+				components++
+				fill(x, y, seen, anyOpen)
+			}
+			if degrees[y][x] == 2 && !inCorridor[y][x] {
+				t.Corridors = append(t.Corridors, fill(x, y, inCorridor, isCorridor))
+			}
+		}
+	}
+	t.Loops = edges - t.OpenCells + components
+	return t
+}
+
+// Solution returns a shortest orthogonal path from Start to End, both
+// included, or nil if End cannot be reached.
+func (l *Layout) Solution() []Cell {
+	previous := make([][]Cell, l.Height)
+	reached := make([][]bool, l.Height)
+	for y := range previous {
+		previous[y] = make([]Cell, l.Width)
+		reached[y] = make([]bool, l.Width)
+	}
+
+	var buf [4]Cell
+	reached[l.Start.Y][l.Start.X] = true
+	queue := []Cell{{X: l.Start.X, Y: l.Start.Y}}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		if cell.X == l.End.X && cell.Y == l.End.Y {
+			path := []Cell{cell}
+			for cell.X != l.Start.X || cell.Y != l.Start.Y {
+				cell = previous[cell.Y][cell.X]
+				path = append(path, cell)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, n := range l.openNeighbors(int(cell.X), int(cell.Y), &buf) {
+			if !reached[n.Y][n.X] {
+				reached[n.Y][n.X] = true
+				previous[n.Y][n.X] = cell
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil
+}
+
+// Stats summarizes the topology and the solution of a layout in numbers
+// that can be attached to every run on it.
+type Stats struct {
+	DeadEnds  int `json:"deadEnds"`
+	Junctions int `json:"junctions"`
+	Loops     int `json:"loops"`
+	// MeanCorridor and MaxCorridor describe the corridor lengths in cells.
+	MeanCorridor float64 `json:"meanCorridor"`
+	MaxCorridor  int     `json:"maxCorridor"`
+	// SolutionLength is the number of cells on a shortest path, zero if the
+	// end cannot be reached, and SolutionFraction its share of the open
+	// cells.
+	SolutionLength   int     `json:"solutionLength"`
+	SolutionFraction float64 `json:"solutionFraction"`
+	// RiverFactor is the mean number of open cells off the solution per
+	// dead end: high for mazes with few long side branches, low for mazes
+	// with many short ones. It is zero without dead ends.
+	RiverFactor float64 `json:"riverFactor"`
+}
+
+// Stats measures the layout.
+func (l *Layout) Stats() Stats {
+	t := l.Topology()
+	s := Stats{
+		DeadEnds:       t.DeadEnds,
+		Junctions:      t.Junctions,
+		Loops:          t.Loops,
+		SolutionLength: len(l.Solution()),
+	}
+	for _, length := range t.Corridors {
+		s.MeanCorridor += float64(length)
+		s.MaxCorridor = max(s.MaxCorridor, length)
+	}
+	if len(t.Corridors) > 0 {
+		s.MeanCorridor /= float64(len(t.Corridors))
+	}
+	if t.OpenCells > 0 {
+		s.SolutionFraction = float64(s.SolutionLength) / float64(t.OpenCells)
+	}
+	if t.DeadEnds > 0 {
+		s.RiverFactor = float64(t.OpenCells-s.SolutionLength) / float64(t.DeadEnds)
+	}
+	return s
+}
+
+// openNeighbors returns the open cells orthogonally adjacent to (x, y),
+// stored in buf.
+func (l *Layout) openNeighbors(x, y int, buf *[4]Cell) []Cell {
+	neighbors := buf[:0]
+	for _, d := range [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		nx, ny := x+d[0], y+d[1]
+		if nx >= 0 && ny >= 0 && nx < l.Width && ny < l.Height && !l.Walls[ny][nx] {
+			neighbors = append(neighbors, Cell{X: uint16(nx), Y: uint16(ny)})
+		}
+	}
+	return neighbors
+}
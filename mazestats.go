@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// runMaze implements the maze command, whose subcommands work on single
+// mazes.
+func runMaze(args []string) error {
+	if len(args) == 0 || args[0] != "stats" {
+		return fmt.Errorf("usage: maze stats [flags] [maze file]")
+	}
+	return runMazeStats(args[1:])
+}
+
+// mazeStatsOutput is the JSON output of the maze stats command.
+type mazeStatsOutput struct {
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	OpenCells int   `json:"openCells"`
+	Corridors []int `json:"corridors"`
+	maze.Stats
+}
+
+// runMazeStats reports the topology and the solution of a maze generated
+// like the runner does, or read from a text file in the format of
+// maze.Layout.WriteText.
+func runMazeStats(args []string) error {
+	flags := flag.NewFlagSet("maze stats", flag.ExitOnError)
+	size := flags.String("size", "51", "Size of the generated maze, 51 or 51x101")
+	seed := flags.Int64("seed", 1, "Seed of the generated maze")
+	generator := flags.String("generator", maze.DefaultGenerator, "Maze generator, one of: "+strings.Join(maze.GeneratorNames(), ", "))
+	singlePath := flags.Bool("single-path", false, "Generate a maze with a single path")
+	loopDensity := flags.Float64("loop-density", 0, "Fraction of the cells opened in a maze with multiple paths (0 for the default)")
+	braid := flags.Float64("braid", 0, "Fraction of the dead ends removed from a maze with multiple paths")
+	loops := flags.Int("loops", 0, "Loops added to a maze with multiple paths after braiding")
+	save := flags.String("save", "", "Also write the maze as text to this file")
+	jsonOutput := flags.Bool("json", false, "Print the statistics as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: maze stats [flags] [maze file]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var layout *maze.Layout
+	switch flags.NArg() {
+	case 0:
+		s, err := parseSize(*size)
+		if err != nil {
+			return err
+		}
+		if *braid < 0 || *braid > 1 || *loops < 0 {
+			return fmt.Errorf("braid must be in [0, 1] and loops must not be negative")
+		}
+		if *singlePath && (*braid > 0 || *loops > 0 || *loopDensity > 0) {
+			return fmt.Errorf("braid, loops and loop-density add paths and cannot be combined with single-path")
+		}
+		layout, err = maze.Generate(maze.Options{
+			Rows:        s.rows,
+			Cols:        s.cols,
+			SinglePath:  *singlePath,
+			Seed:        *seed,
+			Generator:   *generator,
+			LoopDensity: *loopDensity,
+			Braid:       *braid,
+			Loops:       *loops,
+		})
+		if err != nil {
+			return err
+		}
+	case 1:
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if layout, err = maze.ReadText(file); err != nil {
+			return fmt.Errorf("%s: %w", flags.Arg(0), err)
+		}
+	default:
+		flags.Usage()
+		return fmt.Errorf("maze stats takes at most one maze file")
+	}
+
+	if *save != "" {
+		file, err := os.Create(*save)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := layout.WriteText(file); err != nil {
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	topology := layout.Topology()
+	output := mazeStatsOutput{
+		Width:     layout.Width,
+		Height:    layout.Height,
+		OpenCells: topology.OpenCells,
+		Corridors: topology.Corridors,
+		Stats:     layout.Stats(),
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+	printMazeStats(output)
+	return nil
+}
+
+func printMazeStats(s mazeStatsOutput) {
+	fmt.Printf("Size:               %dx%d (rows x cols), %d open cells\n", s.Height, s.Width, s.OpenCells)
+	fmt.Printf("Dead ends:          %d\n", s.DeadEnds)
+	fmt.Printf("Junctions:          %d\n", s.Junctions)
+	fmt.Printf("Cycles:             %d\n", s.Loops)
+	fmt.Printf("Corridors:          %d, mean length %.2f, max %d\n", len(s.Corridors), s.MeanCorridor, s.MaxCorridor)
+	if s.SolutionLength == 0 {
+		fmt.Println("Solution:           end not reachable")
+	} else {
+		fmt.Printf("Solution length:    %d cells, %.2f%% of the open cells\n", s.SolutionLength, 100*s.SolutionFraction)
+	}
+	fmt.Printf("River factor:       %.2f\n", s.RiverFactor)
+
+	if len(s.Corridors) == 0 {
+		return
+	}
+	// Corridor lengths in power of two buckets: 1, 2-3, 4-7, ...
+	fmt.Println("Corridor lengths:")
+	for low := 1; low <= s.MaxCorridor; low *= 2 {
+		high := 2*low - 1
+		count := 0
+		for _, length := range s.Corridors {
+			if length >= low && length <= high {
+				count++
+			}
+		}
+		label := fmt.Sprint(low)
+		if high > low {
+			label = fmt.Sprintf("%d-%d", low, high)
+		}
+		fmt.Printf("  %9s  %d\n", label, count)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"runtime"
+	"time"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// Measurement modes.
+const (
+	// parallelMode runs all algorithms of a maze concurrently; time and
+	// memory include interference from the other algorithms.
+	parallelMode = "parallel"
+	// isolatedMode runs the algorithms one after another, each after a
+	// forced GC, and only times FindPath itself.
+	isolatedMode = "isolated"
+)
+
+// measurement is what is recorded about a single FindPath call.
+type measurement struct {
+	visitedNodes             int
+	nodesInShortestPathOrder []*maze.Node
+	time                     float64 // nanoseconds
+	memoryUsed               float64 // MB
+	allocs                   uint64
+	// Only measured in subprocessMode.
+	wallTime   float64 // nanoseconds, lifetime of the child process
+	peakRSS    float64 // MB
+	pageFaults int64
+}
+
+// usage is the resource usage of a child process.
+type usage struct {
+	peakRSS     float64 // MB
+	minorFaults int64
+	majorFaults int64
+}
+
+// measureShared measures FindPath while other algorithms may be running,
+// as the runner always did: the heap growth is read around the call and
+// the path reconstruction, which are timed together.
+func measureShared(alg algorithms.Algorithm, grid [][]maze.Node, startNode, endNode *maze.Node) measurement {
+	startTime := time.Now()
+	var initialMemoryUsage runtime.MemStats
+	runtime.ReadMemStats(&initialMemoryUsage)
+
+	visitedNodesInOrder := alg.FindPath(grid, startNode, endNode)
+
+	var midMemoryUsage runtime.MemStats
+	runtime.ReadMemStats(&midMemoryUsage)
+
+	nodesInShortestPathOrder := getNodesInShortestPathOrder(endNode)
+
+	var finalMemoryUsage runtime.MemStats
+	runtime.ReadMemStats(&finalMemoryUsage)
+
+	endTime := time.Now()
+	timeTaken := endTime.Sub(startTime).Nanoseconds() // Convert to nanoseconds
+
+	// Check for overflow
+	var memoryUsed float64
+	if finalMemoryUsage.HeapAlloc >= initialMemoryUsage.HeapAlloc {
+		memoryUsed = float64(
+			finalMemoryUsage.HeapAlloc-initialMemoryUsage.HeapAlloc,
+		) / (1024 * 1024) // Convert to MB
+	} else {
+		memoryUsed = 0 // or handle it in another appropriate way
+	}
+
+	return measurement{
+		visitedNodes:             len(visitedNodesInOrder),
+		nodesInShortestPathOrder: nodesInShortestPathOrder,
+		time:                     float64(timeTaken),
+		memoryUsed:               memoryUsed,
+		allocs:                   midMemoryUsage.Mallocs - initialMemoryUsage.Mallocs,
+	}
+}
+
+// measureIsolated measures FindPath on its own. The heap is collected
+// first, and the cumulative TotalAlloc and Mallocs counters are used, so
+// the result neither depends on when the GC runs nor underflows.
+func measureIsolated(
+	alg algorithms.Algorithm,
+	grid [][]maze.Node,
+	startNode, endNode *maze.Node,
+	lockThread bool,
+) measurement {
+	if lockThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	startTime := time.Now()
+	visitedNodesInOrder := alg.FindPath(grid, startNode, endNode)
+	timeTaken := time.Since(startTime).Nanoseconds()
+
+	runtime.ReadMemStats(&after)
+
+	return measurement{
+		visitedNodes:             len(visitedNodesInOrder),
+		nodesInShortestPathOrder: getNodesInShortestPathOrder(endNode),
+		time:                     float64(timeTaken),
+		memoryUsed:               float64(after.TotalAlloc-before.TotalAlloc) / (1024 * 1024),
+		allocs:                   after.Mallocs - before.Mallocs,
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/chart"
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// Chart image formats.
+const (
+	svgFormat = "svg"
+	pngFormat = "png"
+)
+
+const (
+	chartWidth  = 1000
+	chartHeight = 650
+)
+
+// chartGroup returns the group an algorithm is plotted in: Dijkstra and
+// the A* variants, whose times are close, and everything else.
+func chartGroup(algorithm string) string {
+	info, _ := algorithms.Describe(algorithm)
+	if algorithm == "dijkstra" || info.Heuristic != "" {
+		return "dijkstra_astar"
+	}
+	return "other"
+}
+
+// algorithmOrder sorts algorithms in registration order, unknown ones
+// last.
+func algorithmOrder(names []string) {
+	registered := algorithms.Names()
+	rank := func(name string) int {
+		if i := slices.Index(registered, name); i >= 0 {
+			return i
+		}
+		return len(registered)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		if rank(names[i]) != rank(names[j]) {
+			return rank(names[i]) < rank(names[j])
+		}
+		return names[i] < names[j]
+	})
+}
+
+// point returns the mean of the metric and the half width of its 95%
+// confidence interval, zero when only the mean is known.
+func point(entry *resultEntry, metric string, r *rand.Rand) (float64, float64, bool) {
+	if samples := entry.samples[metric]; len(samples) > 0 {
+		summary := stats.Summarize(samples, stats.DefaultMADThreshold, r)
+		return summary.Mean, (summary.CIHigh - summary.CILow) / 2, true
+	}
+	if summary, exists := entry.summaries[metric]; exists {
+		return summary.Mean, (summary.CIHigh - summary.CILow) / 2, true
+	}
+	mean, exists := entry.means[metric]
+	return mean, 0, exists
+}
+
+// sizeAxis returns the label and the x value of every maze size of set:
+// the size when every maze is square, and the number of cells otherwise,
+// so that mazes with the same number of rows are not plotted at the same
+// x. It fails when two sizes have the same number of cells.
+func sizeAxis(set *resultSet) (string, func(resultKey) float64, error) {
+	square := true
+	for key := range set.entries {
+		square = square && key.rows == key.cols
+	}
+	if square {
+		return "Maze size", func(key resultKey) float64 { return float64(key.rows) }, nil
+	}
+
+	sizes := make(map[int]resultKey)
+	for key := range set.entries {
+		cells := key.rows * key.cols
+		if other, exists := sizes[cells]; exists && other.rows != key.rows {
+			return "", nil, fmt.Errorf("%s: sizes %dx%d and %dx%d have the same number of cells and cannot share a chart",
+				set.name, other.rows, other.cols, key.rows, key.cols)
+		}
+		sizes[cells] = key
+	}
+	return "Maze cells (rows x cols)", func(key resultKey) float64 { return float64(key.rows * key.cols) }, nil
+}
+
+// buildCharts returns the charts of a result set for the given metric,
+// keyed by their file name without extension.
+func buildCharts(set *resultSet, metric summarizedMetric) (map[string]*chart.Chart, error) {
+	xLabel, x, err := sizeAxis(set)
+	if err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewSource(1))
+	charts := make(map[string]*chart.Chart)
+	for _, singlePath := range []bool{true, false} {
+		// Series per group and algorithm, with points ordered by size.
+		series := make(map[string]map[string]*chart.Series)
+		var keys []resultKey
+		for key := range set.entries {
+			if key.singlePath == singlePath {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if x(keys[i]) != x(keys[j]) {
+				return x(keys[i]) < x(keys[j])
+			}
+			return keys[i].rows < keys[j].rows
+		})
+
+		for _, key := range keys {
+			mean, err, exists := point(set.entries[key], metric.key, r)
+			if !exists {
+				continue
+			}
+			group := chartGroup(key.algorithm)
+			if series[group] == nil {
+				series[group] = make(map[string]*chart.Series)
+			}
+			s, exists := series[group][key.algorithm]
+			if !exists {
+				s = &chart.Series{Name: key.algorithm}
+				series[group][key.algorithm] = s
+			}
+			s.X = append(s.X, x(key))
+			s.Y = append(s.Y, mean)
+			s.Err = append(s.Err, err)
+		}
+
+		for group, byAlgorithm := range series {
+			var names []string
+			for name := range byAlgorithm {
+				names = append(names, name)
+			}
+			algorithmOrder(names)
+
+			c := &chart.Chart{
+				Title:  fmt.Sprintf("%s - %s (%s)", group, metric.column, mazeType(singlePath)),
+				XLabel: xLabel,
+				YLabel: metric.header(),
+			}
+			for _, name := range names {
+				c.Series = append(c.Series, *byAlgorithm[name])
+			}
+			pathType := "multiplePaths"
+			if singlePath {
+				pathType = "singlePath"
+			}
+			charts[fmt.Sprintf("%s_%s_%s", group, metric.column, pathType)] = c
+		}
+	}
+	return charts, nil
+}
+
+// runPlot implements the plot command, which charts every metric of a
+// result directory over maze size.
+func runPlot(args []string) error {
+	flags := flag.NewFlagSet("plot", flag.ExitOnError)
+	outputDir := flags.String("o", "graphs", "Output directory")
+	format := flags.String("format", svgFormat, "Image format: "+svgFormat+", "+pngFormat+" or "+bothFormat)
+	metricsList := flags.String("metrics", "time,memoryUsed", "Comma-separated metrics to plot: "+strings.Join(summarizedMetricKeys(), ", "))
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: plot [flags] <result dir>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("plot needs one result directory")
+	}
+	if *format != svgFormat && *format != pngFormat && *format != bothFormat {
+		return fmt.Errorf("format must be %s, %s or %s", svgFormat, pngFormat, bothFormat)
+	}
+
+	var metrics []summarizedMetric
+	for _, key := range strings.Split(*metricsList, ",") {
+		index := slices.IndexFunc(summarizedMetrics, func(m summarizedMetric) bool { return m.key == key })
+		if index < 0 {
+			return fmt.Errorf("unknown metric %q, available: %s", key, strings.Join(summarizedMetricKeys(), ", "))
+		}
+		metrics = append(metrics, summarizedMetrics[index])
+	}
+
+	set, err := loadResultSet(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
+		return err
+	}
+
+	for _, metric := range metrics {
+		charts, err := buildCharts(set, metric)
+		if err != nil {
+			return err
+		}
+		for name, c := range charts {
+			if *format != pngFormat {
+				if err := writeChart(filepath.Join(*outputDir, name+".svg"), c.SVG); err != nil {
+					return err
+				}
+			}
+			if *format != svgFormat {
+				if err := writeChart(filepath.Join(*outputDir, name+".png"), c.PNG); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func writeChart(filename string, render func(w io.Writer, width, height int) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Printf("Writing %s\n", filename)
+	if err := render(file, chartWidth, chartHeight); err != nil {
+		return err
+	}
+	return file.Close()
+}
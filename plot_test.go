@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildChartsSizeAxis(t *testing.T) {
+	time := summarizedMetrics[0]
+	newSet := func(sizes ...[2]int) *resultSet {
+		set := &resultSet{name: "test", entries: make(map[resultKey]*resultEntry)}
+		for i, size := range sizes {
+			entry := set.entry(resultKey{rows: size[0], cols: size[1], algorithm: "bfs", singlePath: true})
+			entry.means[time.key] = float64(i + 1)
+		}
+		return set
+	}
+
+	tests := []struct {
+		name  string
+		sizes [][2]int
+		label string
+		x, y  []float64
+	}{
+		{"square", [][2]int{{101, 101}, {51, 51}}, "Maze size", []float64{51, 101}, []float64{2, 1}},
+		{"same rows", [][2]int{{51, 101}, {51, 51}, {101, 51}}, "Maze cells (rows x cols)", []float64{2601, 5151}, nil},
+		{"rectangular", [][2]int{{51, 201}, {101, 51}}, "Maze cells (rows x cols)", []float64{5151, 10251}, []float64{2, 1}},
+	}
+	for _, test := range tests {
+		charts, err := buildCharts(newSet(test.sizes...), time)
+		if test.y == nil {
+			if err == nil {
+				t.Errorf("%s: sizes with the same number of cells were accepted", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		c := charts["other_Time_singlePath"]
+		if c == nil || len(c.Series) != 1 {
+			t.Fatalf("%s: got charts %v", test.name, charts)
+		}
+		if c.XLabel != test.label || !reflect.DeepEqual(c.Series[0].X, test.x) || !reflect.DeepEqual(c.Series[0].Y, test.y) {
+			t.Errorf("%s: got %q %v %v, want %q %v %v", test.name, c.XLabel, c.Series[0].X, c.Series[0].Y, test.label, test.x, test.y)
+		}
+	}
+}
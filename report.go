@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"metric": func(r summaryResult, key string) string {
+		summary, exists := r.Metrics[key]
+		if !exists || summary.N == 0 {
+			return "N/A"
+		}
+		return fmt.Sprintf("%.2f", summary.Mean)
+	},
+	"ci": func(r summaryResult, key string) string {
+		summary := r.Metrics[key]
+		if summary.N == 0 || summary.Mean == 0 {
+			return ""
+		}
+		return fmt.Sprintf("±%.0f%%", (summary.CIHigh-summary.CILow)/2/summary.Mean*100)
+	},
+	"mazeType": mazeType,
+}).Parse(reportTemplateText))
+
+// reportMetrics are charted in the report.
+var reportMetrics = []string{"time", "visitedNodes", "pathCost", "memoryUsed"}
+
+// sampleMazeMaxSize caps the size of the sample maze so that the report
+// stays small.
+const sampleMazeMaxSize = 51
+
+// reportChart is an inline SVG chart.
+type reportChart struct {
+	Title string
+	SVG   template.HTML
+}
+
+// discrepancy counts the runs of a heuristic search whose path cost more
+// than Dijkstra's at one maze size.
+type discrepancy struct {
+	Algorithm  string
+	Heuristic  string
+	Admissible bool
+	Counts     []int
+	Runs       []int
+}
+
+// sampleSolution is the explored region and path of one algorithm on the
+// sample maze.
+type sampleSolution struct {
+	Algorithm    string
+	VisitedNodes int
+	PathLength   int
+	SVG          template.HTML
+}
+
+type reportData struct {
+	Environment   environment
+	Config        resultConfig
+	Documents     []*resultDocument
+	Charts        []reportChart
+	Sizes         []int
+	Discrepancies []discrepancy
+	SampleRows    int
+	SampleCols    int
+	SampleSeed    int64
+	// SampleOfSweep is false when the first maze of the sweep is too large
+	// and the sample is a separate, smaller maze.
+	SampleOfSweep bool
+	Samples       []sampleSolution
+}
+
+// loadResultDocuments reads every JSON result of dir, ordered by maze size.
+func loadResultDocuments(dir string) ([]*resultDocument, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "averages*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var documents []*resultDocument
+	for _, file := range files {
+		document, err := readResultDocument(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		documents = append(documents, document)
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("%s: no JSON results, run with -format %s or %s", dir, jsonFormat, bothFormat)
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		a, b := documents[i].Config, documents[j].Config
+		if a.Rows != b.Rows {
+			return a.Rows < b.Rows
+		}
+		return a.Cols < b.Cols
+	})
+	return documents, nil
+}
+
+// writeReport writes report.html for the results in dir.
+func writeReport(dir string) error {
+	documents, err := loadResultDocuments(dir)
+	if err != nil {
+		return err
+	}
+	set, err := loadResultSet(dir)
+	if err != nil {
+		return err
+	}
+
+	latest := documents[len(documents)-1]
+	data := reportData{
+		Environment: latest.Environment,
+		Config:      latest.Config,
+		Documents:   documents,
+	}
+
+	for _, key := range reportMetrics {
+		for _, m := range summarizedMetrics {
+			if m.key != key {
+				continue
+			}
+			charts, err := buildCharts(set, m)
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(charts))
+			for name := range charts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var svg bytes.Buffer
+				if err := charts[name].SVG(&svg, chartWidth, chartHeight); err != nil {
+					return err
+				}
+				data.Charts = append(data.Charts, reportChart{Title: charts[name].Title, SVG: template.HTML(svg.String())})
+			}
+		}
+	}
+
+	data.Sizes, data.Discrepancies = discrepancies(documents)
+
+	if err := addSampleMaze(&data, documents[0].Config); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := reportTemplate.Execute(&out, data); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "report.html"), out.Bytes(), 0o644)
+}
+
+// discrepancies counts, per maze size, the runs in which each A* variant
+// found a costlier path than Dijkstra.
+func discrepancies(documents []*resultDocument) ([]int, []discrepancy) {
+	var sizes []int
+	byAlgorithm := make(map[string]*discrepancy)
+	var order []string
+	for i, document := range documents {
+		sizes = append(sizes, document.Config.Rows)
+		connectivity := algorithms.Connectivity(document.Config.Connectivity)
+		for _, result := range document.Results {
+			info, exists := algorithms.Describe(result.Algorithm)
+			if !exists || info.Heuristic == "" {
+				continue
+			}
+			d, exists := byAlgorithm[result.Algorithm]
+			if !exists {
+				d = &discrepancy{
+					Algorithm:  result.Algorithm,
+					Heuristic:  info.Heuristic,
+					Admissible: algorithms.IsAdmissible(info.Heuristic, connectivity),
+					Counts:     make([]int, len(documents)),
+					Runs:       make([]int, len(documents)),
+				}
+				byAlgorithm[result.Algorithm] = d
+				order = append(order, result.Algorithm)
+			}
+			d.Counts[i] += result.SuboptimalRuns
+			d.Runs[i] += result.Metrics["time"].N
+		}
+	}
+
+	var list []discrepancy
+	for _, algorithm := range order {
+		list = append(list, *byAlgorithm[algorithm])
+	}
+	return sizes, list
+}
+
+// addSampleMaze solves the first multiple path maze of the sweep, whose
+// configuration is config, with every algorithm of the configuration. When
+// that maze is larger than sampleMazeMaxSize, a separate maze of that size
+// is generated with the same options instead.
+func addSampleMaze(data *reportData, config resultConfig) error {
+	rows, cols := config.Rows, config.Cols
+	if cols == 0 {
+		cols = rows
+	}
+	data.SampleOfSweep = rows <= sampleMazeMaxSize && cols <= sampleMazeMaxSize
+	if !data.SampleOfSweep {
+		rows, cols = min(rows, sampleMazeMaxSize), min(cols, sampleMazeMaxSize)
+	}
+	seed := maze.DeriveSeed(config.Seed, 1)
+	layout, err := maze.Generate(maze.Options{
+		Rows:        rows,
+		Cols:        cols,
+		Seed:        seed,
+		Generator:   config.Generator,
+		Terrain:     config.Terrain,
+		LoopDensity: config.LoopDensity,
+		Braid:       config.Braid,
+		Loops:       config.Loops,
+	})
+	if err != nil {
+		return err
+	}
+	data.SampleRows, data.SampleCols, data.SampleSeed = rows, cols, seed
+
+	movement := algorithms.Movement{
+		Connectivity:    algorithms.Connectivity(config.Connectivity),
+		NoCornerCutting: config.NoCornerCutting,
+	}
+	for _, name := range config.Algorithms {
+		if _, exists := algorithms.Describe(name); !exists {
+			if _, err := algorithms.RegisterAstar(strings.TrimPrefix(name, "astar-")); err != nil {
+				return fmt.Errorf("sample maze: %w", err)
+			}
+		}
+		alg, err := algorithms.New(name, algorithms.Options{Movement: movement})
+		if err != nil {
+			return err
+		}
+		grid, startNode, endNode := layout.NewGrid(1)
+		visited := alg.FindPath(grid, startNode, endNode)
+		path := getNodesInShortestPathOrder(endNode)
+		data.Samples = append(data.Samples, sampleSolution{
+			Algorithm:    name,
+			VisitedNodes: len(visited),
+			PathLength:   len(path),
+			SVG:          mazeSVG(layout, visited, path),
+		})
+	}
+	return nil
+}
+
+// mazeSVG draws layout with the visited cells and the path highlighted.
+func mazeSVG(layout *maze.Layout, visited []maze.Node, path []*maze.Node) template.HTML {
+	const cell = 6
+	classes := make([][]string, layout.Height)
+	for y := range classes {
+		classes[y] = make([]string, layout.Width)
+		for x := range classes[y] {
+			if layout.Walls[y][x] {
+				classes[y][x] = "wall"
+			}
+		}
+	}
+	for _, node := range visited {
+		classes[node.Y][node.X] = "visited"
+	}
+	for _, node := range path {
+		classes[node.Y][node.X] = "path"
+	}
+	classes[layout.Start.Y][layout.Start.X] = "start"
+	classes[layout.End.Y][layout.End.X] = "end"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="maze" width="%d" height="%d">`, layout.Width*cell, layout.Height*cell)
+	for y, row := range classes {
+		// Runs of equal cells are drawn as a single rectangle.
+		for x := 0; x < len(row); {
+			end := x
+			for end < len(row) && row[end] == row[x] {
+				end++
+			}
+			if row[x] != "" {
+				fmt.Fprintf(&b, `<rect class="%s" x="%d" y="%d" width="%d" height="%d"/>`,
+					row[x], x*cell, y*cell, (end-x)*cell, cell)
+			}
+			x = end
+		}
+	}
+	b.WriteString("</svg>")
+	return template.HTML(b.String())
+}
+
+// runReport implements the report command, which writes report.html for a
+// result directory.
+func runReport(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: report <result dir>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("report needs one result directory")
+	}
+	if err := writeReport(flags.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Printf("Writing %s\n", filepath.Join(flags.Arg(0), "report.html"))
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// resultSchemaVersion is incremented whenever the layout of resultDocument
+// changes in a way readers have to know about.
+const resultSchemaVersion = 1
+
+// Result document formats.
+const (
+	csvFormat  = "csv"
+	jsonFormat = "json"
+	bothFormat = "both"
+)
+
+// resultDocument is the JSON result of one maze size, the versioned
+// counterpart of the averages CSV.
+type resultDocument struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Environment   environment  `json:"environment"`
+	Config        resultConfig `json:"config"`
+	// Units maps metric keys to the unit of their summaries; metrics
+	// without an entry are counts.
+	Units   map[string]string `json:"units"`
+	Results []summaryResult   `json:"results"`
+}
+
+// resultConfig is the full configuration a result was produced with.
+type resultConfig struct {
+	Rows            int      `json:"rows"`
+	Cols            int      `json:"cols"`
+	NumTests        int      `json:"numTests"`
+	Seed            int64    `json:"seed"`
+	Algorithms      []string `json:"algorithms"`
+	Generator       string   `json:"generator"`
+	Terrain         bool     `json:"terrain"`
+	LoopDensity     float64  `json:"loopDensity"`
+	Braid           float64  `json:"braid,omitempty"`
+	Loops           int      `json:"loops,omitempty"`
+	Connectivity    int      `json:"connectivity"`
+	NoCornerCutting bool     `json:"noCornerCutting"`
+	Mode            string   `json:"mode"`
+	Warmup          int      `json:"warmup"`
+	Workers         int      `json:"workers,omitempty"`
+	LockThread      bool     `json:"lockThread"`
+	MADThreshold    float64  `json:"madThreshold"`
+	Marker          string   `json:"marker,omitempty"`
+	Experiment      string   `json:"experiment,omitempty"`
+	RunsFormats     []string `json:"runsFormats,omitempty"`
+}
+
+// summaryResult is one algorithm's entry in a resultDocument.
+type summaryResult struct {
+	Algorithm  string `json:"algorithm"`
+	SinglePath bool   `json:"singlePath"`
+	algorithmSummary
+}
+
+func newResultDocument(
+	cfg runConfig,
+	numRows, numCols, numTests int,
+	summariesSPOn, summariesSPOff map[string]*algorithmSummary,
+) resultDocument {
+	document := resultDocument{
+		SchemaVersion: resultSchemaVersion,
+		Environment:   captureEnvironment(time.Now()),
+		Config: resultConfig{
+			Rows:            numRows,
+			Cols:            numCols,
+			NumTests:        numTests,
+			Seed:            cfg.seed,
+			Algorithms:      cfg.algorithms,
+			Generator:       cfg.generator,
+			Terrain:         cfg.terrain,
+			LoopDensity:     cfg.loopDensity,
+			Braid:           cfg.braid,
+			Loops:           cfg.loops,
+			Connectivity:    int(cfg.movement.Connectivity),
+			NoCornerCutting: cfg.movement.NoCornerCutting,
+			Mode:            cfg.mode,
+			Warmup:          cfg.warmup,
+			Workers:         cfg.workers,
+			LockThread:      cfg.lockThread,
+			MADThreshold:    cfg.madThreshold,
+			Marker:          cfg.marker,
+			Experiment:      cfg.experiment,
+			RunsFormats:     cfg.runsFormats,
+		},
+		Units: make(map[string]string),
+	}
+	for _, m := range summarizedMetrics {
+		if m.unit != "" && !(m.notInThroughput && cfg.mode == throughputMode) {
+			document.Units[m.key] = m.unit
+		}
+	}
+	for _, singlePath := range []bool{true, false} {
+		summaries := summariesSPOff
+		if singlePath {
+			summaries = summariesSPOn
+		}
+		for _, algorithm := range cfg.algorithms {
+			if summary, exists := summaries[algorithm]; exists {
+				document.Results = append(document.Results, summaryResult{algorithm, singlePath, *summary})
+			}
+		}
+	}
+	return document
+}
+
+func writeResultsToJson(filename string, document resultDocument) error {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0o644)
+}
+
+// readResultDocument loads a result written by writeResultsToJson.
+func readResultDocument(filename string) (*resultDocument, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var document resultDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.SchemaVersion != resultSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d, want %d", document.SchemaVersion, resultSchemaVersion)
+	}
+	return &document, nil
+}
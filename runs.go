@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/writer"
+
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// Per-run export formats.
+const (
+	runsCSV     = "csv"
+	runsJSONL   = "jsonl"
+	runsParquet = "parquet"
+)
+
+var runsFormats = []string{runsCSV, runsJSONL, runsParquet}
+
+// runRecord is one run of one algorithm in the long-format per-run export.
+// The subprocess-only metrics are nil outside subprocessMode, and time,
+// memory and allocations are nil in throughputMode.
+type runRecord struct {
+	Run               int      `json:"run" parquet:"name=run, type=INT64"`
+	Seed              int64    `json:"seed" parquet:"name=seed, type=INT64"`
+	Algorithm         string   `json:"algorithm" parquet:"name=algorithm, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SinglePath        bool     `json:"singlePath" parquet:"name=singlePath, type=BOOLEAN"`
+	Rows              int      `json:"rows" parquet:"name=rows, type=INT64"`
+	Cols              int      `json:"cols" parquet:"name=cols, type=INT64"`
+	DeadEnds          int      `json:"deadEnds" parquet:"name=deadEnds, type=INT64"`
+	Junctions         int      `json:"junctions" parquet:"name=junctions, type=INT64"`
+	Loops             int      `json:"loops" parquet:"name=loops, type=INT64"`
+	MeanCorridor      float64  `json:"meanCorridor" parquet:"name=meanCorridor, type=DOUBLE"`
+	MaxCorridor       int      `json:"maxCorridor" parquet:"name=maxCorridor, type=INT64"`
+	SolutionLength    int      `json:"solutionLength" parquet:"name=solutionLength, type=INT64"`
+	SolutionFraction  float64  `json:"solutionFraction" parquet:"name=solutionFraction, type=DOUBLE"`
+	RiverFactor       float64  `json:"riverFactor" parquet:"name=riverFactor, type=DOUBLE"`
+	TimeMs            *float64 `json:"timeMs,omitempty" parquet:"name=timeMs, type=DOUBLE, repetitiontype=OPTIONAL"`
+	VisitedNodes      int      `json:"visitedNodes" parquet:"name=visitedNodes, type=INT64"`
+	VisitedPercentage float64  `json:"visitedPercentage" parquet:"name=visitedPercentage, type=DOUBLE"`
+	PathLength        int      `json:"pathLength" parquet:"name=pathLength, type=INT64"`
+	PathCost          float64  `json:"pathCost" parquet:"name=pathCost, type=DOUBLE"`
+	MemoryUsedMB      *float64 `json:"memoryUsedMB,omitempty" parquet:"name=memoryUsedMB, type=DOUBLE, repetitiontype=OPTIONAL"`
+	Allocs            *int64   `json:"allocs,omitempty" parquet:"name=allocs, type=INT64, repetitiontype=OPTIONAL"`
+	WallTimeMs        *float64 `json:"wallTimeMs,omitempty" parquet:"name=wallTimeMs, type=DOUBLE, repetitiontype=OPTIONAL"`
+	PeakRSSMB         *float64 `json:"peakRSSMB,omitempty" parquet:"name=peakRSSMB, type=DOUBLE, repetitiontype=OPTIONAL"`
+	PageFaults        *int64   `json:"pageFaults,omitempty" parquet:"name=pageFaults, type=INT64, repetitiontype=OPTIONAL"`
+	Verified          bool     `json:"verified" parquet:"name=verified, type=BOOLEAN"`
+	Optimal           bool     `json:"optimal" parquet:"name=optimal, type=BOOLEAN"`
+	Suboptimal        bool     `json:"suboptimal" parquet:"name=suboptimal, type=BOOLEAN"`
+	// TimeOutlier reports whether the run's time exceeds the MAD threshold
+	// among the runs of the same algorithm and maze type.
+	TimeOutlier bool `json:"timeOutlier" parquet:"name=timeOutlier, type=BOOLEAN"`
+}
+
+// parseRunsFormats validates a comma-separated list of export formats.
+func parseRunsFormats(list string) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var formats []string
+	for _, format := range strings.Split(list, ",") {
+		valid := false
+		for _, known := range runsFormats {
+			valid = valid || format == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown per-run format %q, available: %s", format, strings.Join(runsFormats, ", "))
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// collectRuns flattens the metrics of both maze types into run records, in
+// the order of cfg.algorithms.
+func collectRuns(cfg runConfig, numRows, numCols int, metricsSPOn, metricsSPOff map[string]*Metrics) []runRecord {
+	var records []runRecord
+	for _, singlePath := range []bool{true, false} {
+		metrics := metricsSPOff
+		if singlePath {
+			metrics = metricsSPOn
+		}
+		for _, algorithm := range cfg.algorithms {
+			metric, exists := metrics[algorithm]
+			if !exists {
+				continue
+			}
+			outliers := stats.Outliers(metric.Time, cfg.madThreshold)
+			for i := range metric.Time {
+				record := runRecord{
+					Run:               i,
+					Seed:              metric.Seeds[i],
+					Algorithm:         algorithm,
+					SinglePath:        singlePath,
+					Rows:              numRows,
+					Cols:              numCols,
+					DeadEnds:          metric.Mazes[i].DeadEnds,
+					Junctions:         metric.Mazes[i].Junctions,
+					Loops:             metric.Mazes[i].Loops,
+					MeanCorridor:      metric.Mazes[i].MeanCorridor,
+					MaxCorridor:       metric.Mazes[i].MaxCorridor,
+					SolutionLength:    metric.Mazes[i].SolutionLength,
+					SolutionFraction:  metric.Mazes[i].SolutionFraction,
+					RiverFactor:       metric.Mazes[i].RiverFactor,
+					VisitedNodes:      metric.VisitedNodes[i],
+					VisitedPercentage: metric.VisitedPercentage[i],
+					PathLength:        metric.PathLength[i],
+					PathCost:          metric.PathCost[i],
+					Verified:          metric.Verified[i],
+					Optimal:           metric.Optimal[i],
+					Suboptimal:        metric.Suboptimal[i],
+					TimeOutlier:       outliers[i],
+				}
+				if cfg.mode != throughputMode {
+					timeMs, allocs := metric.Time[i]/1e6, int64(metric.Allocs[i])
+					record.TimeMs = &timeMs
+					record.MemoryUsedMB = &metric.MemoryUsed[i]
+					record.Allocs = &allocs
+				}
+				if cfg.mode == subprocessMode {
+					wallTime := metric.WallTime[i] / 1e6
+					record.WallTimeMs = &wallTime
+					record.PeakRSSMB = &metric.PeakRSS[i]
+					record.PageFaults = &metric.PageFaults[i]
+				}
+				records = append(records, record)
+			}
+		}
+	}
+	return records
+}
+
+// writeRuns writes records to basename plus the extension of every format
+// in cfg.runsFormats.
+func writeRuns(basename string, cfg runConfig, records []runRecord) error {
+	for _, format := range cfg.runsFormats {
+		var err error
+		filename := basename + "." + format
+		switch format {
+		case runsCSV:
+			err = writeRunsToCsv(filename, records)
+		case runsJSONL:
+			err = writeRunsToJsonl(filename, records)
+		case runsParquet:
+			err = writeRunsToParquet(filename, records)
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+func writeRunsToCsv(filename string, records []runRecord) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	header := []string{
+		"Run",
+		"Seed",
+		"Algorithm",
+		"SinglePath",
+		"Rows",
+		"Cols",
+		"DeadEnds",
+		"Junctions",
+		"Loops",
+		"MeanCorridor",
+		"MaxCorridor",
+		"SolutionLength",
+		"SolutionFraction",
+		"RiverFactor",
+		"Time [ms]",
+		"VisitedNodes",
+		"VisitedPercentage [%]",
+		"PathLength",
+		"PathCost",
+		"MemoryUsed [MB]",
+		"Allocs",
+		"WallTime [ms]",
+		"PeakRSS [MB]",
+		"PageFaults",
+		"Verified",
+		"Optimal",
+		"Suboptimal",
+		"TimeOutlier",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, r := range records {
+		timeMs, memoryUsed, allocs := "N/A", "N/A", "N/A"
+		if r.TimeMs != nil {
+			timeMs = strconv.FormatFloat(*r.TimeMs, 'f', -1, 64)
+			memoryUsed = strconv.FormatFloat(*r.MemoryUsedMB, 'f', -1, 64)
+			allocs = strconv.FormatInt(*r.Allocs, 10)
+		}
+		wallTime, peakRSS, pageFaults := "N/A", "N/A", "N/A"
+		if r.WallTimeMs != nil {
+			wallTime = strconv.FormatFloat(*r.WallTimeMs, 'f', -1, 64)
+			peakRSS = strconv.FormatFloat(*r.PeakRSSMB, 'f', -1, 64)
+			pageFaults = strconv.FormatInt(*r.PageFaults, 10)
+		}
+		row := []string{
+			strconv.Itoa(r.Run),
+			strconv.FormatInt(r.Seed, 10),
+			r.Algorithm,
+			strconv.FormatBool(r.SinglePath),
+			strconv.Itoa(r.Rows),
+			strconv.Itoa(r.Cols),
+			strconv.Itoa(r.DeadEnds),
+			strconv.Itoa(r.Junctions),
+			strconv.Itoa(r.Loops),
+			strconv.FormatFloat(r.MeanCorridor, 'f', -1, 64),
+			strconv.Itoa(r.MaxCorridor),
+			strconv.Itoa(r.SolutionLength),
+			strconv.FormatFloat(r.SolutionFraction, 'f', -1, 64),
+			strconv.FormatFloat(r.RiverFactor, 'f', -1, 64),
+			timeMs,
+			strconv.Itoa(r.VisitedNodes),
+			strconv.FormatFloat(r.VisitedPercentage, 'f', -1, 64),
+			strconv.Itoa(r.PathLength),
+			strconv.FormatFloat(r.PathCost, 'f', -1, 64),
+			memoryUsed,
+			allocs,
+			wallTime,
+			peakRSS,
+			pageFaults,
+			strconv.FormatBool(r.Verified),
+			strconv.FormatBool(r.Optimal),
+			strconv.FormatBool(r.Suboptimal),
+			strconv.FormatBool(r.TimeOutlier),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeRunsToJsonl(filename string, records []runRecord) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeRunsToParquet(filename string, records []runRecord) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	pw, err := writer.NewParquetWriterFromWriter(file, new(runRecord), 1)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := pw.Write(r); err != nil {
+			return err
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+)
+
+// processUsage is not supported on this platform.
+func processUsage(state *os.ProcessState) usage {
+	return usage{}
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// processUsage extracts the resource usage of an exited child process.
+func processUsage(state *os.ProcessState) usage {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return usage{}
+	}
+
+	// Maxrss is reported in kilobytes on Linux but in bytes on macOS.
+	peakRSS := float64(rusage.Maxrss) / 1024
+	if runtime.GOOS == "darwin" {
+		peakRSS /= 1024
+	}
+
+	return usage{
+		peakRSS:     peakRSS,
+		minorFaults: int64(rusage.Minflt),
+		majorFaults: int64(rusage.Majflt),
+	}
+}
@@ -24,21 +24,16 @@ type Metrics struct {
 	VisitedNodes      []int     `json:"visitedNodes"`
 	VisitedPercentage []float64 `json:"visitedPercentage"`
 	PathLength        []int     `json:"pathLength"`
+	PathCost          []float64 `json:"pathCost"`
 	MemoryUsed        []float64 `json:"memoryUsed"`
+	Seed              int64     `json:"seed"`
 }
 
 var (
-	algorithmsMap = map[string]algorithms.Algorithm{
-		"dijkstra":     algorithms.Dijkstra{},
-		"astar":        algorithms.Astar{},
-		"bfs":          algorithms.BFS{},
-		"dfs":          algorithms.DFS{},
-		"wallFollower": algorithms.WallFollower{},
-	}
-
 	grids        map[string][][]maze.Node
 	startNodes   map[string]*maze.Node
 	endNodes     map[string]*maze.Node
+	mazeSeed     int64
 	metrics      map[string]*Metrics
 	metricsMutex = &sync.Mutex{}
 )
@@ -57,6 +52,8 @@ func main() {
 	// Routes
 	router.GET("/api/maze", mazeHandler)
 	router.GET("/api/solution", solutionHandler)
+	router.GET("/api/algorithms", algorithmsHandler)
+	router.GET("/api/generators", generatorsHandler)
 
 	router.Run("localhost:5000")
 }
@@ -64,6 +61,9 @@ func main() {
 func mazeHandler(c *gin.Context) {
 	mazeSizeStr := c.Query("mazeSize")
 	singlePathStr := c.Query("singlePath")
+	seedStr := c.Query("seed")
+	generator := c.Query("generator")
+	terrainStr := c.Query("terrain")
 
 	if mazeSizeStr == "" {
 		mazeSizeStr = "50"
@@ -84,74 +84,127 @@ func mazeHandler(c *gin.Context) {
 		return
 	}
 
-	grids, startNodes, endNodes = getInitialGrid(mazeSize, mazeSize, singlePath)
+	terrain := false
+	if terrainStr != "" {
+		terrain, err = strconv.ParseBool(terrainStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid terrain"})
+			return
+		}
+	}
+
+	seed := time.Now().UnixNano()
+	if seedStr != "" {
+		seed, err = strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid seed"})
+			return
+		}
+	}
+
+	newGrids, newStartNodes, newEndNodes, err := getInitialGrid(maze.Options{
+		Rows:       mazeSize,
+		Cols:       mazeSize,
+		SinglePath: singlePath,
+		Seed:       seed,
+		Generator:  generator,
+		Terrain:    terrain,
+	})
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	grids, startNodes, endNodes = newGrids, newStartNodes, newEndNodes
+	mazeSeed = seed
 
 	c.JSON(200, gin.H{
-		"grids": map[string][][]maze.Node{
-			"dijkstra":     grids["dijkstra"],
-			"astar":        grids["astar"],
-			"bfs":          grids["bfs"],
-			"dfs":          grids["dfs"],
-			"wallFollower": grids["wallFollower"],
-		},
-		"startNodes": map[string]*maze.Node{
-			"dijkstra":     startNodes["dijkstra"],
-			"astar":        startNodes["astar"],
-			"bfs":          startNodes["bfs"],
-			"dfs":          startNodes["dfs"],
-			"wallFollower": startNodes["wallFollower"],
-		},
-		"endNodes": map[string]*maze.Node{
-			"dijkstra":     endNodes["dijkstra"],
-			"astar":        endNodes["astar"],
-			"bfs":          endNodes["bfs"],
-			"dfs":          endNodes["dfs"],
-			"wallFollower": endNodes["wallFollower"],
-		},
+		"seed":       seed,
+		"grids":      grids,
+		"startNodes": startNodes,
+		"endNodes":   endNodes,
 	})
 }
 
+func algorithmsHandler(c *gin.Context) {
+	infos := make([]algorithms.Info, 0, len(algorithms.Names()))
+	for _, algorithm := range algorithms.Names() {
+		info, _ := algorithms.Describe(algorithm)
+		infos = append(infos, info)
+	}
+
+	c.JSON(200, gin.H{"algorithms": infos})
+}
+
+func generatorsHandler(c *gin.Context) {
+	c.JSON(200, gin.H{"generators": maze.GeneratorNames()})
+}
+
 func getInitialGrid(
-	numRows, numCols int,
-	singlePath bool,
-) (map[string][][]maze.Node, map[string]*maze.Node, map[string]*maze.Node) {
+	opts maze.Options,
+) (map[string][][]maze.Node, map[string]*maze.Node, map[string]*maze.Node, error) {
 	grids := make(map[string][][]maze.Node)
 	startNodes := make(map[string]*maze.Node)
 	endNodes := make(map[string]*maze.Node)
 
-	mazeData := maze.GenerateMaze(numRows, numCols, singlePath)
+	layout, err := maze.Generate(opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i, algorithm := range algorithms.Names() {
+		grids[algorithm], startNodes[algorithm], endNodes[algorithm] = layout.NewGrid(uint16(i + 1))
+	}
 
-	grids["dijkstra"] = mazeData["gridDijkstra"].([][]maze.Node)
-	grids["astar"] = mazeData["gridAstar"].([][]maze.Node)
-	grids["bfs"] = mazeData["gridBFS"].([][]maze.Node)
-	grids["dfs"] = mazeData["gridDFS"].([][]maze.Node)
-	grids["wallFollower"] = mazeData["gridWallFollower"].([][]maze.Node)
+	return grids, startNodes, endNodes, nil
+}
 
-	startNodes["dijkstra"] = mazeData["gridDijkstraStartNode"].(*maze.Node)
-	startNodes["astar"] = mazeData["gridAstarStartNode"].(*maze.Node)
-	startNodes["bfs"] = mazeData["gridBFSStartNode"].(*maze.Node)
-	startNodes["dfs"] = mazeData["gridDFSStartNode"].(*maze.Node)
-	startNodes["wallFollower"] = mazeData["gridWallFollowerStartNode"].(*maze.Node)
+func solutionHandler(c *gin.Context) {
+	connectivityStr := c.Query("connectivity")
+	noCornerCuttingStr := c.Query("noCornerCutting")
 
-	endNodes["dijkstra"] = mazeData["gridDijkstraEndNode"].(*maze.Node)
-	endNodes["astar"] = mazeData["gridAstarEndNode"].(*maze.Node)
-	endNodes["bfs"] = mazeData["gridBFSEndNode"].(*maze.Node)
-	endNodes["dfs"] = mazeData["gridDFSEndNode"].(*maze.Node)
-	endNodes["wallFollower"] = mazeData["gridWallFollowerEndNode"].(*maze.Node)
+	if connectivityStr == "" {
+		connectivityStr = "4"
+	}
+	if noCornerCuttingStr == "" {
+		noCornerCuttingStr = "false"
+	}
 
-	return grids, startNodes, endNodes
-}
+	connectivity, err := strconv.Atoi(connectivityStr)
+	if err != nil || (connectivity != 4 && connectivity != 8) {
+		c.JSON(400, gin.H{"error": "Invalid connectivity"})
+		return
+	}
+
+	noCornerCutting, err := strconv.ParseBool(noCornerCuttingStr)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid noCornerCutting"})
+		return
+	}
+
+	opts := algorithms.Options{
+		Movement: algorithms.Movement{
+			Connectivity:    algorithms.Connectivity(connectivity),
+			NoCornerCutting: noCornerCutting,
+		},
+	}
 
-func solutionHandler(c *gin.Context) {
 	var wg sync.WaitGroup
 	compressedResults := make(map[string]interface{})
 
 	// Reset metrics
 	metricsMutex.Lock()
 	metrics = initializeMetrics()
+	for _, m := range metrics {
+		m.Seed = mazeSeed
+	}
 	metricsMutex.Unlock()
 
-	for algorithm, alg := range algorithmsMap {
+	for _, algorithm := range algorithms.Names() {
+		alg, err := algorithms.New(algorithm, opts)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
 		wg.Add(1)
 		go func(algorithm string, alg algorithms.Algorithm) {
 			defer wg.Done()
@@ -161,6 +214,7 @@ func solutionHandler(c *gin.Context) {
 
 			nodesInShortestPathOrder, visitedNodesInOrder := runAlgorithm(
 				algorithm,
+				alg,
 				grid,
 				startNode,
 				endNode,
@@ -214,17 +268,16 @@ func compressNodeList(nodes interface{}) [][]int {
 }
 
 func initializeMetrics() map[string]*Metrics {
-	return map[string]*Metrics{
-		"dijkstra":     {},
-		"astar":        {},
-		"bfs":          {},
-		"dfs":          {},
-		"wallFollower": {},
+	metrics := make(map[string]*Metrics)
+	for _, algorithm := range algorithms.Names() {
+		metrics[algorithm] = &Metrics{}
 	}
+	return metrics
 }
 
 func runAlgorithm(
 	algorithm string,
+	alg algorithms.Algorithm,
 	grid [][]maze.Node,
 	startNode *maze.Node,
 	endNode *maze.Node,
@@ -233,7 +286,7 @@ func runAlgorithm(
 	var initialMemoryUsage runtime.MemStats
 	runtime.ReadMemStats(&initialMemoryUsage)
 
-	visitedNodesInOrder := algorithmsMap[algorithm].FindPath(grid, startNode, endNode)
+	visitedNodesInOrder := alg.FindPath(grid, startNode, endNode)
 
 	var midMemoryUsage runtime.MemStats
 	runtime.ReadMemStats(&midMemoryUsage)
@@ -274,6 +327,10 @@ func runAlgorithm(
 		metrics[algorithm].PathLength,
 		len(nodesInShortestPathOrder),
 	)
+	metrics[algorithm].PathCost = append(
+		metrics[algorithm].PathCost,
+		algorithms.PathCost(nodesInShortestPathOrder),
+	)
 	metrics[algorithm].MemoryUsed = append(metrics[algorithm].MemoryUsed, memoryUsed)
 	metricsMutex.Unlock()
 
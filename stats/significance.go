@@ -0,0 +1,132 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of
+// whether x and y come from the same distribution, as benchstat uses. It
+// uses the normal approximation with tie and continuity corrections, which
+// is conservative for the small samples typical of benchmarks.
+func MannWhitneyU(x, y []float64) float64 {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type sample struct {
+		value float64
+		first bool
+	}
+	samples := make([]sample, 0, len(x)+len(y))
+	for _, v := range x {
+		samples = append(samples, sample{v, true})
+	}
+	for _, v := range y {
+		samples = append(samples, sample{v, false})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+
+	// Tied values share the average of their ranks.
+	rankSum, tieCorrection := 0.0, 0.0
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].first {
+				rankSum += rank
+			}
+		}
+		ties := float64(j - i)
+		tieCorrection += ties*ties*ties - ties
+		i = j
+	}
+
+	n := n1 + n2
+	u := rankSum - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// WelchTTest returns the two-sided p-value of Welch's t-test of whether the
+// means summarised by a and b are equal. It is used when only summaries and
+// not the individual runs are available.
+func WelchTTest(a, b Summary) float64 {
+	if a.N < 2 || b.N < 2 {
+		return 1
+	}
+
+	va, vb := a.StdDev*a.StdDev/float64(a.N), b.StdDev*b.StdDev/float64(b.N)
+	if va+vb == 0 {
+		if a.Mean == b.Mean {
+			return 1
+		}
+		return 0
+	}
+	t := (a.Mean - b.Mean) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(a.N-1) + vb*vb/float64(b.N-1))
+	return regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// regularizedIncompleteBeta computes I_x(a, b) with the continued fraction
+// from Numerical Recipes.
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		epsilon = 1e-14
+		tiny    = 1e-300
+	)
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1.0; m <= 300; m++ {
+		numerator := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 / clamp(1+numerator*d)
+		c = clamp(1 + numerator/c)
+		h *= d * c
+
+		numerator = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 / clamp(1+numerator*d)
+		c = clamp(1 + numerator/c)
+		h *= d * c
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
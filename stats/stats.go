@@ -0,0 +1,172 @@
+// Package stats summarises the repeated measurements of a benchmark.
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DefaultMADThreshold is the robust z-score above which a run is flagged as
+// an outlier, as recommended by Iglewicz and Hoaglin.
+const DefaultMADThreshold = 3.5
+
+// bootstrapResamples is the number of resamples drawn for the confidence
+// interval of the mean.
+const bootstrapResamples = 1000
+
+// Summary describes the distribution of one metric over a set of runs.
+type Summary struct {
+	N      int     `json:"n"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stdDev"`
+	P90    float64 `json:"p90"`
+	P99    float64 `json:"p99"`
+	// CILow and CIHigh bound the 95% bootstrap confidence interval of the
+	// mean.
+	CILow  float64 `json:"ciLow"`
+	CIHigh float64 `json:"ciHigh"`
+	// Outliers counts the runs flagged by Outliers.
+	Outliers int `json:"outliers"`
+}
+
+// Summarize computes the summary of values. The bootstrap resamples are
+// drawn from r so that summaries are reproducible.
+func Summarize(values []float64, madThreshold float64, r *rand.Rand) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	summary := Summary{
+		N:      len(values),
+		Mean:   Mean(values),
+		Median: Percentile(sorted, 50),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		StdDev: StdDev(values),
+		P90:    Percentile(sorted, 90),
+		P99:    Percentile(sorted, 99),
+	}
+	summary.CILow, summary.CIHigh = BootstrapMeanCI(values, 0.95, bootstrapResamples, r)
+	for _, outlier := range Outliers(values, madThreshold) {
+		if outlier {
+			summary.Outliers++
+		}
+	}
+	return summary
+}
+
+// Mean returns the arithmetic mean of values.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// StdDev returns the sample standard deviation of values.
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := Mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// Percentile returns the p-th percentile (0..100) of the sorted values,
+// interpolating linearly between the closest ranks.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// Median returns the median of values.
+func Median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return Percentile(sorted, 50)
+}
+
+// BootstrapMeanCI returns the percentile bootstrap confidence interval of
+// the mean of values at the given level, e.g. 0.95.
+func BootstrapMeanCI(values []float64, level float64, resamples int, r *rand.Rand) (float64, float64) {
+	if len(values) < 2 {
+		mean := Mean(values)
+		return mean, mean
+	}
+
+	means := make([]float64, resamples)
+	for i := range means {
+		sum := 0.0
+		for range values {
+			sum += values[r.Intn(len(values))]
+		}
+		means[i] = sum / float64(len(values))
+	}
+	sort.Float64s(means)
+
+	alpha := (1 - level) / 2
+	return Percentile(means, alpha*100), Percentile(means, (1-alpha)*100)
+}
+
+// Outliers flags the values whose robust z-score, the distance from the
+// median in units of the scaled median absolute deviation, exceeds
+// threshold. Nothing is flagged when more than half of the values are equal
+// to the median, since the deviation is then zero.
+func Outliers(values []float64, threshold float64) []bool {
+	flags := make([]bool, len(values))
+	if len(values) < 3 {
+		return flags
+	}
+
+	median := Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	// 1.4826 makes the MAD a consistent estimator of the standard
+	// deviation for normally distributed data.
+	mad := 1.4826 * Median(deviations)
+	if mad == 0 {
+		return flags
+	}
+
+	for i, deviation := range deviations {
+		flags[i] = deviation/mad > threshold
+	}
+	return flags
+}
+
+// Scale converts the summary to another unit by multiplying every value by
+// factor.
+func (s Summary) Scale(factor float64) Summary {
+	s.Mean *= factor
+	s.Median *= factor
+	s.Min *= factor
+	s.Max *= factor
+	s.StdDev *= factor
+	s.P90 *= factor
+	s.P99 *= factor
+	s.CILow *= factor
+	s.CIHigh *= factor
+	return s
+}
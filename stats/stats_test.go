@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	values := []float64{4, 1, 3, 2, 5}
+	s := Summarize(values, DefaultMADThreshold, rand.New(rand.NewSource(1)))
+
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"mean", s.Mean, 3},
+		{"median", s.Median, 3},
+		{"min", s.Min, 1},
+		{"max", s.Max, 5},
+		{"stdDev", s.StdDev, math.Sqrt(2.5)},
+		{"p90", s.P90, 4.6},
+		{"p99", s.P99, 4.96},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if s.N != 5 || s.Outliers != 0 {
+		t.Errorf("n = %d, outliers = %d, want 5 and 0", s.N, s.Outliers)
+	}
+	if s.CILow > s.Mean || s.CIHigh < s.Mean || s.CILow < s.Min || s.CIHigh > s.Max {
+		t.Errorf("confidence interval [%v, %v] does not bracket the mean %v", s.CILow, s.CIHigh, s.Mean)
+	}
+
+	again := Summarize(values, DefaultMADThreshold, rand.New(rand.NewSource(1)))
+	if again != s {
+		t.Errorf("same seed gave %+v, then %+v", s, again)
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	values := []float64{10, 11, 9, 10, 12, 10, 50}
+	flags := Outliers(values, DefaultMADThreshold)
+	for i, flag := range flags {
+		if flag != (i == len(values)-1) {
+			t.Errorf("value %v flagged %t", values[i], flag)
+		}
+	}
+
+	for _, flag := range Outliers([]float64{1, 1, 1, 1, 7}, DefaultMADThreshold) {
+		if flag {
+			t.Error("flagged a run although the median absolute deviation is zero")
+		}
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	y := []float64{6, 7, 8, 9, 10}
+	// The exact two-sided p-value is 2/252, the normal approximation is a
+	// little more conservative.
+	if p := MannWhitneyU(x, y); p < 2.0/252 || p > 0.02 {
+		t.Errorf("p = %v for disjoint samples", p)
+	}
+	if p := MannWhitneyU(x, x); p != 1 {
+		t.Errorf("p = %v for identical samples, want 1", p)
+	}
+	if p := MannWhitneyU([]float64{3, 3, 3}, []float64{3, 3}); p != 1 {
+		t.Errorf("p = %v for constant samples, want 1", p)
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	// With equal variances and sizes Welch's test matches Student's, and
+	// t = 2.228 is the two-sided 5% critical value for 10 degrees of
+	// freedom.
+	a := Summary{N: 6, Mean: 2.228, StdDev: math.Sqrt(3)}
+	b := Summary{N: 6, Mean: 0, StdDev: math.Sqrt(3)}
+	if p := WelchTTest(a, b); math.Abs(p-0.05) > 1e-3 {
+		t.Errorf("p = %v, want 0.05", p)
+	}
+	if p := WelchTTest(a, a); p != 1 {
+		t.Errorf("p = %v for equal means, want 1", p)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// subprocessMode runs every (algorithm, maze) pair in a fresh child process
+// so that its peak resident memory and page faults can be measured.
+const subprocessMode = "subprocess"
+
+// childFlag is the hidden flag that turns the runner into a child process
+// which reads a childJob from stdin and writes a childResult to stdout.
+const childFlag = "child"
+
+type childJob struct {
+	Layout     *maze.Layout
+	Algorithm  string
+	Movement   algorithms.Movement
+	Warmup     int
+	LockThread bool
+}
+
+type childResult struct {
+	VisitedNodes int
+	// Path holds the (x, y) coordinates of the shortest path.
+	Path       [][2]uint16
+	Time       float64
+	MemoryUsed float64
+	Allocs     uint64
+}
+
+// runChild solves a single job read from stdin in isolated mode.
+func runChild() error {
+	// An interrupt of the runner lets the current job finish.
+	signal.Ignore(os.Interrupt)
+
+	var job childJob
+	if err := gob.NewDecoder(os.Stdin).Decode(&job); err != nil {
+		return fmt.Errorf("decoding job: %w", err)
+	}
+
+	// A* variants given with -heuristics are only registered in the parent.
+	if _, exists := algorithms.Describe(job.Algorithm); !exists && strings.HasPrefix(job.Algorithm, "astar-") {
+		if _, err := algorithms.RegisterAstar(strings.TrimPrefix(job.Algorithm, "astar-")); err != nil {
+			return err
+		}
+	}
+	alg, err := algorithms.New(job.Algorithm, algorithms.Options{Movement: job.Movement})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < job.Warmup; i++ {
+		grid, startNode, endNode := job.Layout.NewGrid(1)
+		alg.FindPath(grid, startNode, endNode)
+	}
+
+	grid, startNode, endNode := job.Layout.NewGrid(1)
+	m := measureIsolated(alg, grid, startNode, endNode, job.LockThread)
+
+	result := childResult{
+		VisitedNodes: m.visitedNodes,
+		Path:         make([][2]uint16, len(m.nodesInShortestPathOrder)),
+		Time:         m.time,
+		MemoryUsed:   m.memoryUsed,
+		Allocs:       m.allocs,
+	}
+	for i, node := range m.nodesInShortestPathOrder {
+		result.Path[i] = [2]uint16{node.X, node.Y}
+	}
+	return gob.NewEncoder(os.Stdout).Encode(result)
+}
+
+// measureSubprocess re-executes the runner to solve layout with algorithm
+// in a child process. The path is mapped back onto grid, a fresh grid of
+// the same layout, so that it can be verified by the parent.
+func measureSubprocess(algorithm string, cfg runConfig, layout *maze.Layout, grid [][]maze.Node) (measurement, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return measurement{}, err
+	}
+
+	var stdin, stdout, stderr bytes.Buffer
+	job := childJob{
+		Layout:     layout,
+		Algorithm:  algorithm,
+		Movement:   cfg.movement,
+		Warmup:     cfg.warmup,
+		LockThread: cfg.lockThread,
+	}
+	if err := gob.NewEncoder(&stdin).Encode(job); err != nil {
+		return measurement{}, err
+	}
+
+	cmd := exec.Command(executable, "-"+childFlag)
+	cmd.Stdin = &stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	startTime := time.Now()
+	if err := cmd.Run(); err != nil {
+		return measurement{}, fmt.Errorf("child for %s failed: %w: %s", algorithm, err, stderr.String())
+	}
+	wallTime := time.Since(startTime).Nanoseconds()
+
+	var result childResult
+	if err := gob.NewDecoder(&stdout).Decode(&result); err != nil {
+		return measurement{}, fmt.Errorf("decoding result of %s: %w", algorithm, err)
+	}
+
+	path := make([]*maze.Node, len(result.Path))
+	for i, p := range result.Path {
+		path[i] = &grid[p[1]][p[0]]
+	}
+
+	usage := processUsage(cmd.ProcessState)
+	cfg.memory.observe(usage.peakRSS)
+	return measurement{
+		visitedNodes:             result.VisitedNodes,
+		nodesInShortestPathOrder: path,
+		time:                     result.Time,
+		memoryUsed:               result.MemoryUsed,
+		allocs:                   result.Allocs,
+		wallTime:                 float64(wallTime),
+		peakRSS:                  usage.peakRSS,
+		pageFaults:               usage.minorFaults + usage.majorFaults,
+	}, nil
+}
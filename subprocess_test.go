@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+	"pathfinding_algorithms_test_runner/verify"
+)
+
+// TestMain lets the test binary act as the child process of subprocess
+// mode, which re-executes os.Executable with -child.
+func TestMain(m *testing.M) {
+	if len(os.Args) == 2 && os.Args[1] == "-"+childFlag {
+		if err := runChild(); err != nil {
+			os.Stderr.WriteString(err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestSubprocessRuntimeHeuristic(t *testing.T) {
+	// The child does not see the registration made here, as for a
+	// -heuristics flag of the parent.
+	name, err := algorithms.RegisterAstar("weighted-manhattan:1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := maze.Generate(maze.Options{Rows: 21, Cols: 21, Seed: 4, LoopDensity: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := runConfig{algorithms: []string{name}, mode: subprocessMode}
+
+	grid, _, _ := layout.NewGrid(1)
+	m, err := measureSubprocess(name, cfg, layout, grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	truth := verify.Solve(layout, cfg.movement)
+	if result := verify.Check(layout, cfg.movement, truth, m.nodesInShortestPathOrder); !result.Passed() {
+		t.Errorf("child path is invalid: %v", result.Err)
+	}
+	if m.visitedNodes == 0 {
+		t.Error("child visited no nodes")
+	}
+}
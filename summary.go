@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// summarizedMetric describes how a per-run metric is summarised and
+// written out.
+type summarizedMetric struct {
+	key    string
+	column string
+	unit   string
+	format string
+	// scale converts the recorded value to unit.
+	scale float64
+	// subprocessOnly metrics are only measured in subprocessMode.
+	subprocessOnly bool
+	// notInThroughput metrics are measured around a single run and are not
+	// reported in throughputMode, where the other workers distort them.
+	notInThroughput bool
+}
+
+var summarizedMetrics = []summarizedMetric{
+	{key: "time", column: "Time", unit: "ms", format: "%.2f", scale: 1e-6, notInThroughput: true},
+	{key: "visitedNodes", column: "VisitedNodes", format: "%.0f", scale: 1},
+	{key: "visitedPercentage", column: "VisitedPercentage", unit: "%", format: "%.2f", scale: 1},
+	{key: "pathLength", column: "PathLength", format: "%.2f", scale: 1},
+	{key: "pathCost", column: "PathCost", format: "%.2f", scale: 1},
+	{key: "memoryUsed", column: "MemoryUsed", unit: "MB", format: "%.2f", scale: 1, notInThroughput: true},
+	{key: "allocs", column: "Allocs", format: "%.0f", scale: 1, notInThroughput: true},
+	{key: "wallTime", column: "WallTime", unit: "ms", format: "%.2f", scale: 1e-6, subprocessOnly: true},
+	{key: "peakRSS", column: "PeakRSS", unit: "MB", format: "%.2f", scale: 1, subprocessOnly: true},
+	{key: "pageFaults", column: "PageFaults", format: "%.0f", scale: 1, subprocessOnly: true},
+	{key: "deadEnds", column: "DeadEnds", format: "%.2f", scale: 1},
+	{key: "loops", column: "Loops", format: "%.2f", scale: 1},
+	{key: "solutionFraction", column: "SolutionFraction", format: "%.4f", scale: 1},
+	{key: "riverFactor", column: "RiverFactor", format: "%.2f", scale: 1},
+}
+
+// measured reports whether the metric is measured in the mode of cfg.
+func (m summarizedMetric) measured(cfg runConfig) bool {
+	if m.subprocessOnly && cfg.mode != subprocessMode {
+		return false
+	}
+	return !m.notInThroughput || cfg.mode != throughputMode
+}
+
+// header is the CSV column of the metric's mean.
+func (m summarizedMetric) header() string {
+	if m.unit == "" {
+		return m.column
+	}
+	return m.column + " [" + m.unit + "]"
+}
+
+// values returns the recorded runs of the metric with the given key.
+func (m *Metrics) values(key string) []float64 {
+	var values []float64
+	switch key {
+	case "time":
+		values = m.Time
+	case "visitedNodes":
+		for _, v := range m.VisitedNodes {
+			values = append(values, float64(v))
+		}
+	case "visitedPercentage":
+		values = m.VisitedPercentage
+	case "pathLength":
+		for _, v := range m.PathLength {
+			values = append(values, float64(v))
+		}
+	case "pathCost":
+		values = m.PathCost
+	case "memoryUsed":
+		values = m.MemoryUsed
+	case "allocs":
+		for _, v := range m.Allocs {
+			values = append(values, float64(v))
+		}
+	case "wallTime":
+		values = m.WallTime
+	case "peakRSS":
+		values = m.PeakRSS
+	case "pageFaults":
+		for _, v := range m.PageFaults {
+			values = append(values, float64(v))
+		}
+	case "deadEnds":
+		for _, s := range m.Mazes {
+			values = append(values, float64(s.DeadEnds))
+		}
+	case "loops":
+		for _, s := range m.Mazes {
+			values = append(values, float64(s.Loops))
+		}
+	case "solutionFraction":
+		for _, s := range m.Mazes {
+			values = append(values, s.SolutionFraction)
+		}
+	case "riverFactor":
+		for _, s := range m.Mazes {
+			values = append(values, s.RiverFactor)
+		}
+	}
+	return values
+}
+
+// algorithmSummary summarises all runs of one algorithm. Metric summaries
+// are converted to the unit of their summarizedMetric.
+type algorithmSummary struct {
+	Metrics        map[string]stats.Summary `json:"metrics"`
+	SuboptimalRuns int                      `json:"suboptimalRuns"`
+	FailedRuns     int                      `json:"failedRuns"`
+}
+
+// calculateSummaries summarises the metrics of every algorithm. The
+// bootstrap is seeded from the master seed, so the same run always yields
+// the same confidence intervals.
+func calculateSummaries(cfg runConfig, metrics map[string]*Metrics) map[string]*algorithmSummary {
+	r := rand.New(rand.NewSource(cfg.seed))
+	summaries := make(map[string]*algorithmSummary)
+	for _, algorithm := range cfg.algorithms {
+		metric, exists := metrics[algorithm]
+		if !exists {
+			continue
+		}
+
+		summary := &algorithmSummary{Metrics: make(map[string]stats.Summary)}
+		for _, m := range summarizedMetrics {
+			if m.notInThroughput && cfg.mode == throughputMode {
+				continue
+			}
+			summary.Metrics[m.key] = stats.Summarize(metric.values(m.key), cfg.madThreshold, r).Scale(m.scale)
+		}
+		for i := range metric.Time {
+			if metric.Suboptimal[i] {
+				summary.SuboptimalRuns++
+			}
+			if !metric.Verified[i] {
+				summary.FailedRuns++
+			}
+		}
+		summaries[algorithm] = summary
+	}
+	return summaries
+}
+
+// summaryColumns are the CSV columns written for every summarised metric
+// next to its mean.
+func summaryColumns() []string {
+	var columns []string
+	for _, m := range summarizedMetrics {
+		unit := ""
+		if m.unit != "" {
+			unit = " [" + m.unit + "]"
+		}
+		for _, statistic := range []string{"Median", "Min", "Max", "StdDev", "P90", "P99", "CI95Low", "CI95High"} {
+			columns = append(columns, m.column+" "+statistic+unit)
+		}
+		columns = append(columns, m.column+" Outliers")
+	}
+	return columns
+}
+
+// summaryRow formats the summaryColumns of summary.
+func summaryRow(cfg runConfig, summary *algorithmSummary) []string {
+	var row []string
+	for _, m := range summarizedMetrics {
+		s := summary.Metrics[m.key]
+		format := func(value float64) string {
+			if !m.measured(cfg) {
+				return "N/A"
+			}
+			return fmt.Sprintf(m.format, value)
+		}
+		outliers := strconv.Itoa(s.Outliers)
+		if !m.measured(cfg) {
+			outliers = "N/A"
+		}
+		row = append(row,
+			format(s.Median),
+			format(s.Min),
+			format(s.Max),
+			format(s.StdDev),
+			format(s.P90),
+			format(s.P99),
+			format(s.CILow),
+			format(s.CIHigh),
+			outliers,
+		)
+	}
+	return row
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSizes continues from 25 in steps of 25 until a budget runs out or a
+// test fails, as the runner always did.
+const defaultSizes = "25::25"
+
+// sweepSize is the maze size of one step of a sweep.
+type sweepSize struct {
+	rows, cols int
+}
+
+func (s sweepSize) String() string {
+	if s.rows == s.cols {
+		return strconv.Itoa(s.rows)
+	}
+	return fmt.Sprintf("%dx%d", s.rows, s.cols)
+}
+
+func (s sweepSize) cells() float64 {
+	return float64(s.rows) * float64(s.cols)
+}
+
+// sizeRange is one item of a sweep specification: a single size, or square
+// sizes from first up to stop, stepping by step cells or by factor.
+type sizeRange struct {
+	first sweepSize
+	// stop is the inclusive upper bound, zero for an unbounded range.
+	stop   int
+	step   int
+	factor float64
+}
+
+// next returns the size following s, false at the end of the range.
+func (r sizeRange) next(s sweepSize) (sweepSize, bool) {
+	var size int
+	switch {
+	case r.step > 0:
+		size = s.rows + r.step
+	case r.factor > 0:
+		// Tiny sizes are rounded back to themselves, so the range always
+		// advances by at least one cell.
+		size = max(int(math.Round(float64(s.rows)*r.factor)), s.rows+1)
+	default:
+		return s, false
+	}
+	if r.stop > 0 && size > r.stop {
+		return s, false
+	}
+	return sweepSize{size, size}, true
+}
+
+// sweep is the sequence of sizes of a sweep specification.
+type sweep []sizeRange
+
+// each calls fn with every size of the sweep until it returns false.
+func (s sweep) each(fn func(size sweepSize) bool) {
+	for _, r := range s {
+		for size, ok := r.first, true; ok; size, ok = r.next(size) {
+			if !fn(size) {
+				return
+			}
+		}
+	}
+}
+
+// parseSize parses a square size "51" or a rectangular "51x101".
+func parseSize(s string) (sweepSize, error) {
+	rows, cols, rectangular := strings.Cut(s, "x")
+	r, err := strconv.Atoi(rows)
+	if err != nil || r < 3 {
+		return sweepSize{}, fmt.Errorf("invalid maze size %q", s)
+	}
+	if !rectangular {
+		return sweepSize{r, r}, nil
+	}
+	c, err := strconv.Atoi(cols)
+	if err != nil || c < 3 {
+		return sweepSize{}, fmt.Errorf("invalid maze size %q", s)
+	}
+	return sweepSize{r, c}, nil
+}
+
+// parseSizes parses a comma-separated sweep specification. Every item is a
+// size ("51" or "51x101") or a range of square sizes "start:stop:step",
+// where a step of "x1.5" grows the size geometrically and an empty stop,
+// allowed for the last item only, never ends the sweep.
+func parseSizes(spec string) (sweep, error) {
+	items := strings.Split(spec, ",")
+	var s sweep
+	for i, item := range items {
+		parts := strings.Split(item, ":")
+		switch len(parts) {
+		case 1:
+			size, err := parseSize(item)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, sizeRange{first: size})
+		case 3:
+			r, err := parseRange(parts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size range %q: %w", item, err)
+			}
+			if r.stop == 0 && i != len(items)-1 {
+				return nil, fmt.Errorf("invalid size range %q: only the last range may be unbounded", item)
+			}
+			s = append(s, r)
+		default:
+			return nil, fmt.Errorf("invalid size range %q, want start:stop:step", item)
+		}
+	}
+	return s, nil
+}
+
+func parseRange(parts []string) (sizeRange, error) {
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start < 3 {
+		return sizeRange{}, fmt.Errorf("start must be at least 3")
+	}
+	r := sizeRange{first: sweepSize{start, start}}
+	if parts[1] != "" {
+		if r.stop, err = strconv.Atoi(parts[1]); err != nil || r.stop < start {
+			return sizeRange{}, fmt.Errorf("stop must be empty or at least the start")
+		}
+	}
+	if factor, geometric := strings.CutPrefix(parts[2], "x"); geometric {
+		if r.factor, err = strconv.ParseFloat(factor, 64); err != nil || r.factor <= 1 {
+			return sizeRange{}, fmt.Errorf("factor must be greater than 1")
+		}
+	} else if r.step, err = strconv.Atoi(parts[2]); err != nil || r.step < 1 {
+		return sizeRange{}, fmt.Errorf("step must be a positive integer or a factor like x2")
+	}
+	return r, nil
+}
+
+// testCounts is the number of tests per size: a default, lowered or raised
+// from given row counts on.
+type testCounts struct {
+	base       int
+	thresholds []testThreshold
+}
+
+type testThreshold struct {
+	rows, tests int
+}
+
+// parseTestCounts parses "10" or "10,201=5,1001=2", where 201=5 runs 5
+// tests for every maze with at least 201 rows.
+func parseTestCounts(spec string) (testCounts, error) {
+	items := strings.Split(spec, ",")
+	base, err := strconv.Atoi(items[0])
+	if err != nil || base < 1 {
+		return testCounts{}, fmt.Errorf("invalid test count %q", items[0])
+	}
+	counts := testCounts{base: base}
+	for _, item := range items[1:] {
+		rows, tests, found := strings.Cut(item, "=")
+		var t testThreshold
+		var rowsErr, testsErr error
+		t.rows, rowsErr = strconv.Atoi(rows)
+		t.tests, testsErr = strconv.Atoi(tests)
+		if !found || rowsErr != nil || testsErr != nil || t.tests < 1 {
+			return testCounts{}, fmt.Errorf("invalid test count %q, want size=tests", item)
+		}
+		counts.thresholds = append(counts.thresholds, t)
+	}
+	sort.Slice(counts.thresholds, func(i, j int) bool { return counts.thresholds[i].rows < counts.thresholds[j].rows })
+	return counts, nil
+}
+
+// forSize returns the number of tests for a maze size.
+func (c testCounts) forSize(size sweepSize) int {
+	tests := c.base
+	for _, t := range c.thresholds {
+		if size.rows >= t.rows {
+			tests = t.tests
+		}
+	}
+	return tests
+}
+
+// budget stops a sweep before a size that is expected to exceed it. Zero
+// fields are unlimited.
+type budget struct {
+	wallTime time.Duration
+	memoryMB float64
+	// started is when the time budget began, shared by the sweeps of an
+	// experiment; the start of the sweep if zero.
+	started time.Time
+}
+
+// sweepStep is the cost of a finished size, used to extrapolate the next.
+type sweepStep struct {
+	size     sweepSize
+	tests    int
+	duration time.Duration
+	// baseMB is the memory of the runner when the size started, and
+	// memoryMB the peak while it ran, of the runner or of a child process.
+	baseMB   float64
+	memoryMB float64
+}
+
+// exceeded returns why the next size is expected to exceed the budget,
+// scaling the cost of the previous size by the number of cells and tests.
+func (b budget) exceeded(elapsed time.Duration, last *sweepStep, next sweepSize, tests int) string {
+	if b.wallTime > 0 && elapsed >= b.wallTime {
+		return fmt.Sprintf("time budget of %s used up", b.wallTime)
+	}
+	if last == nil {
+		return ""
+	}
+	scale := next.cells() / last.size.cells()
+	if b.wallTime > 0 {
+		estimate := time.Duration(float64(last.duration) * scale * float64(tests) / float64(last.tests))
+		if elapsed+estimate > b.wallTime {
+			return fmt.Sprintf("size %s would take about %s, only %s of the time budget is left",
+				next, estimate.Round(time.Second), (b.wallTime - elapsed).Round(time.Second))
+		}
+	}
+	if b.memoryMB > 0 {
+		// Only the memory the size added grows with the cells.
+		if estimate := last.baseMB + max(last.memoryMB-last.baseMB, 0)*scale; estimate > b.memoryMB {
+			return fmt.Sprintf("size %s would need about %.0f MB, over the memory budget of %.0f MB",
+				next, estimate, b.memoryMB)
+		}
+	}
+	return ""
+}
+
+// runSweep runs the tests of every size of the sweep, stopping at the first
+// failure or once the budget would be exceeded.
+func runSweep(cfg runConfig, sizes sweep, tests testCounts, b budget) error {
+	start := b.started
+	if start.IsZero() {
+		start = time.Now()
+	}
+	var last *sweepStep
+	var err error
+	sizes.each(func(size sweepSize) bool {
+		numTests := tests.forSize(size)
+		if reason := b.exceeded(time.Since(start), last, size, numTests); reason != "" {
+			fmt.Printf("Stopping the sweep: %s\n", reason)
+			return false
+		}
+
+		fmt.Printf("Running %d tests with maze size %s\n", numTests, size)
+		sizeStart := time.Now()
+		sampler := startMemorySampler()
+		sizeCfg := cfg
+		sizeCfg.memory = sampler
+		err = runTest(size.rows, size.cols, numTests, sizeCfg)
+		base, peak := sampler.stop()
+		if err != nil {
+			if errors.Is(err, errInterrupted) {
+				return false
+			}
+			fmt.Printf("Test failed for maze size %s: %s\n", size, err.Error())
+			return false
+		}
+		last = &sweepStep{size, numTests, time.Since(sizeStart), base, peak}
+		return true
+	})
+	return err
+}
+
+// memorySampleInterval is how often a memorySampler reads the memory of the
+// runner. Shorter peaks are missed, which is fine for a budget estimate.
+const memorySampleInterval = 10 * time.Millisecond
+
+// memorySampler records the peak memory of one size of a sweep: the memory
+// the Go runtime holds from the operating system, sampled while the size
+// runs, and the peak resident set size of the child processes it started.
+// Unlike the process-wide peak of getrusage, it starts afresh every size.
+type memorySampler struct {
+	mu       sync.Mutex
+	baseMB   float64
+	peakMB   float64
+	finished chan struct{}
+	done     sync.WaitGroup
+}
+
+func startMemorySampler() *memorySampler {
+	s := &memorySampler{finished: make(chan struct{})}
+	s.baseMB = runtimeMemory()
+	s.peakMB = s.baseMB
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.observe(runtimeMemory())
+			case <-s.finished:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// observe records a memory reading in MB. It may be called on a nil
+// sampler, outside of sweeps.
+func (s *memorySampler) observe(mb float64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.peakMB = max(s.peakMB, mb)
+	s.mu.Unlock()
+}
+
+// stop ends the sampling and returns the memory at the start and the peak.
+func (s *memorySampler) stop() (baseMB, peakMB float64) {
+	s.observe(runtimeMemory())
+	close(s.finished)
+	s.done.Wait()
+	return s.baseMB, s.peakMB
+}
+
+// runtimeMemory returns the memory in MB the Go runtime holds from the
+// operating system and has not released, which approximates the resident
+// set size of the runner.
+func runtimeMemory() float64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return float64(samples[0].Value.Uint64()-samples[1].Value.Uint64()) / 1024 / 1024
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+func sweepSizes(t *testing.T, spec string, limit int) []sweepSize {
+	t.Helper()
+	s, err := parseSizes(spec)
+	if err != nil {
+		t.Fatalf("parseSizes(%q): %v", spec, err)
+	}
+	var sizes []sweepSize
+	s.each(func(size sweepSize) bool {
+		sizes = append(sizes, size)
+		return len(sizes) < limit
+	})
+	return sizes
+}
+
+func TestParseSizes(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []sweepSize
+	}{
+		{"25:100:25", []sweepSize{{25, 25}, {50, 50}, {75, 75}, {100, 100}}},
+		{"25:250:x2", []sweepSize{{25, 25}, {50, 50}, {100, 100}, {200, 200}}},
+		{"3:5:x1.1", []sweepSize{{3, 3}, {4, 4}, {5, 5}}},
+		{"51,51x101,11:21:10", []sweepSize{{51, 51}, {51, 101}, {11, 11}, {21, 21}}},
+		{"25::25", []sweepSize{{25, 25}, {50, 50}, {75, 75}, {100, 100}, {125, 125}}},
+	}
+	for _, test := range tests {
+		if got := sweepSizes(t, test.spec, 5); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseSizes(%q) = %v, want %v", test.spec, got, test.want)
+		}
+	}
+
+	for _, spec := range []string{"", "2", "51x", "25:10:5", "25:100", "25:100:x1", "25::25,51"} {
+		if _, err := parseSizes(spec); err == nil {
+			t.Errorf("parseSizes(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestTestCounts(t *testing.T) {
+	counts, err := parseTestCounts("10,1001=2,201=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows, want := range map[int]int{25: 10, 201: 5, 999: 5, 1001: 2} {
+		if got := counts.forSize(sweepSize{rows, rows}); got != want {
+			t.Errorf("forSize(%d) = %d, want %d", rows, got, want)
+		}
+	}
+}
+
+func TestSweepAfterLargerSweep(t *testing.T) {
+	cfg := runConfig{
+		outputDir:    t.TempDir(),
+		seed:         1,
+		algorithms:   []string{"dijkstra", "bfs"},
+		generator:    "recursiveBacktracker",
+		loopDensity:  0.1,
+		mode:         isolatedMode,
+		madThreshold: stats.DefaultMADThreshold,
+		format:       csvFormat,
+		quiet:        true,
+	}
+	large, err := parseSizes("801")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runSweep(cfg, large, testCounts{base: 2}, budget{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The budget of the small sweep only depends on its own sizes, not on
+	// the peak of the large sweep before it.
+	small, err := parseSizes("11,21,31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runSweep(cfg, small, testCounts{base: 2}, budget{memoryMB: runtimeMemory() + 64}); err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{11, 21, 31} {
+		name := filepath.Join(cfg.outputDir, fmt.Sprintf("averages%dx%dx2.csv", size, size))
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("size %d was not run: %v", size, err)
+		}
+	}
+}
+
+func TestBudgetMemoryEstimate(t *testing.T) {
+	last := &sweepStep{size: sweepSize{101, 101}, tests: 1, baseMB: 20, memoryMB: 30}
+	b := budget{memoryMB: 100}
+	// 20 MB plus 10 MB scaled by four times the cells.
+	if reason := b.exceeded(0, last, sweepSize{202, 202}, 1); reason != "" {
+		t.Errorf("60 MB exceeded a budget of 100 MB: %s", reason)
+	}
+	if reason := b.exceeded(0, last, sweepSize{404, 404}, 1); reason == "" {
+		t.Error("180 MB did not exceed a budget of 100 MB")
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// throughputMode solves many mazes concurrently on a pool of workers, each
+// running the algorithms of its maze one after another. It is meant for
+// correctness sweeps: path lengths, costs and visited nodes are exact, but
+// time and memory are distorted by the other workers.
+const throughputMode = "throughput"
+
+// mazeJob is a maze for a solver worker together with the algorithms that
+// still have to solve it.
+type mazeJob struct {
+	test       int
+	singlePath bool
+	layout     *maze.Layout
+	algorithms []string
+}
+
+// mazeResult holds one run of every algorithm of a mazeJob.
+type mazeResult struct {
+	mazeJob
+	metrics map[string]*Metrics
+}
+
+// merge appends the runs of other to m.
+func (m *Metrics) merge(other *Metrics) {
+	m.Time = append(m.Time, other.Time...)
+	m.VisitedNodes = append(m.VisitedNodes, other.VisitedNodes...)
+	m.VisitedPercentage = append(m.VisitedPercentage, other.VisitedPercentage...)
+	m.PathLength = append(m.PathLength, other.PathLength...)
+	m.PathCost = append(m.PathCost, other.PathCost...)
+	m.MemoryUsed = append(m.MemoryUsed, other.MemoryUsed...)
+	m.Allocs = append(m.Allocs, other.Allocs...)
+	m.WallTime = append(m.WallTime, other.WallTime...)
+	m.PeakRSS = append(m.PeakRSS, other.PeakRSS...)
+	m.PageFaults = append(m.PageFaults, other.PageFaults...)
+	m.Seeds = append(m.Seeds, other.Seeds...)
+	m.Mazes = append(m.Mazes, other.Mazes...)
+	m.Suboptimal = append(m.Suboptimal, other.Suboptimal...)
+	m.Verified = append(m.Verified, other.Verified...)
+	m.Optimal = append(m.Optimal, other.Optimal...)
+}
+
+// collectMetricsConcurrently is collectMetrics for throughputMode. A
+// generator feeds mazes to cfg.workers solvers through a bounded channel,
+// and the solved mazes are journaled as they arrive and merged in test
+// order once all are done, so the results line up with the other modes.
+// Since the runs distort each other's times, only the aggregate rate of
+// solved mazes is reported. When interrupted, the tests solved by then are
+// merged and returned with errInterrupted.
+func collectMetricsConcurrently(numRows, numCols, numTests int, cfg runConfig) (map[string]*Metrics, map[string]*Metrics, error) {
+	rows, cols := normalizeSize(numRows), normalizeSize(numCols)
+	started := time.Now()
+	jobs := make(chan mazeJob, cfg.workers)
+	results := make(chan mazeResult, cfg.workers)
+	// done stops the generator when the aggregator fails.
+	done := make(chan struct{})
+
+	// The journal is only read here, before the aggregator starts writing.
+	var pending []mazeJob
+	for _, singlePath := range []bool{true, false} {
+		for i := 0; i < numTests; i++ {
+			var missing []string
+			for _, algorithm := range cfg.algorithms {
+				if _, exists := cfg.journal.lookup(journalKey{rows, cols, singlePath, i, algorithm}); !exists {
+					missing = append(missing, algorithm)
+				}
+			}
+			if len(missing) > 0 {
+				pending = append(pending, mazeJob{test: i, singlePath: singlePath, algorithms: missing})
+			}
+		}
+	}
+
+	var generateErr error
+	interrupted := false
+	go func() {
+		defer close(jobs)
+		for _, job := range pending {
+			select {
+			case <-cfg.interrupt:
+				interrupted = true
+				return
+			case <-done:
+				return
+			default:
+			}
+
+			layout, err := generateTestMaze(cfg, numRows, numCols, job.singlePath, job.test)
+			if err != nil {
+				generateErr = err
+				return
+			}
+			job.layout = layout
+
+			select {
+			case jobs <- job:
+			case <-cfg.interrupt:
+				interrupted = true
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var workers sync.WaitGroup
+	for w := 0; w < cfg.workers; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				metrics := initializeMetrics(job.algorithms)
+				solveCfg := cfg
+				solveCfg.algorithms = job.algorithms
+				solveMaze(solveCfg, job.layout, metrics)
+				results <- mazeResult{job, metrics}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	solved := map[bool][]map[string]*Metrics{
+		true:  make([]map[string]*Metrics, numTests),
+		false: make([]map[string]*Metrics, numTests),
+	}
+	var journalErr error
+	completed := 0
+	for result := range results {
+		if journalErr != nil {
+			continue
+		}
+		solved[result.singlePath][result.test] = result.metrics
+		if err := cfg.journal.record(rows, cols, result.singlePath, result.test, result.algorithms, result.metrics); err != nil {
+			journalErr = err
+			close(done)
+			continue
+		}
+		completed++
+		if !cfg.quiet {
+			fmt.Printf("Completed %d mazes for size: %s\n", completed, sweepSize{numRows, numCols})
+		}
+	}
+	if journalErr != nil {
+		return nil, nil, journalErr
+	}
+	if elapsed := time.Since(started); completed > 0 {
+		fmt.Printf("Solved %d mazes of size %s in %s on %d workers, %.1f mazes per second\n",
+			completed, sweepSize{numRows, numCols}, elapsed.Round(time.Millisecond), cfg.workers,
+			float64(completed)/elapsed.Seconds())
+	}
+	if generateErr != nil {
+		return nil, nil, generateErr
+	}
+
+	metricsSPOn := initializeMetrics(cfg.algorithms)
+	metricsSPOff := initializeMetrics(cfg.algorithms)
+	for _, singlePath := range []bool{true, false} {
+		metrics := metricsSPOff
+		if singlePath {
+			metrics = metricsSPOn
+		}
+		merged := 0
+		for i := 0; i < numTests; i++ {
+			if !solvedTest(cfg, rows, cols, singlePath, i, solved[singlePath][i]) {
+				// Only an interrupt leaves tests unsolved.
+				continue
+			}
+			for _, algorithm := range cfg.algorithms {
+				if run, exists := solved[singlePath][i][algorithm]; exists {
+					metrics[algorithm].merge(run)
+				} else {
+					entry, _ := cfg.journal.lookup(journalKey{rows, cols, singlePath, i, algorithm})
+					entry.restore(metrics[algorithm])
+				}
+			}
+			markSuboptimalRuns(metrics, merged)
+			merged++
+		}
+	}
+	if interrupted {
+		return metricsSPOn, metricsSPOff, errInterrupted
+	}
+	return metricsSPOn, metricsSPOff, nil
+}
+
+// solvedTest reports whether every algorithm has a run of the given test,
+// either in solved or in the journal.
+func solvedTest(cfg runConfig, rows, cols int, singlePath bool, test int, solved map[string]*Metrics) bool {
+	for _, algorithm := range cfg.algorithms {
+		if _, exists := solved[algorithm]; exists {
+			continue
+		}
+		if _, exists := cfg.journal.lookup(journalKey{rows, cols, singlePath, test, algorithm}); !exists {
+			return false
+		}
+	}
+	return true
+}
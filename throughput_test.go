@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/stats"
+)
+
+// deterministic returns the metrics without the measured time and memory,
+// which differ between any two runs.
+func deterministic(metrics map[string]*Metrics) map[string]Metrics {
+	runs := make(map[string]Metrics)
+	for algorithm, m := range metrics {
+		run := *m
+		run.Time, run.MemoryUsed, run.Allocs = nil, nil, nil
+		runs[algorithm] = run
+	}
+	return runs
+}
+
+func TestThroughputMergeOrder(t *testing.T) {
+	cfg := runConfig{
+		seed:         5,
+		algorithms:   []string{"dijkstra", "astar", "bfs", "dfs", "wallFollower"},
+		generator:    "recursiveBacktracker",
+		loopDensity:  0.1,
+		mode:         isolatedMode,
+		madThreshold: stats.DefaultMADThreshold,
+		quiet:        true,
+	}
+	wantSPOn, wantSPOff, err := collectMetrics(21, 21, 12, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.mode = throughputMode
+	for _, workers := range []int{1, 2, 3, 8} {
+		cfg.workers = workers
+		metricsSPOn, metricsSPOff, err := collectMetrics(21, 21, 12, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(deterministic(metricsSPOn), deterministic(wantSPOn)) ||
+			!reflect.DeepEqual(deterministic(metricsSPOff), deterministic(wantSPOff)) {
+			t.Errorf("%d workers: runs differ from the sequential ones", workers)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package verify checks the paths returned by pathfinding algorithms against
+// the maze they were run on and against an independently computed shortest
+// path.
+package verify
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+// costTolerance absorbs float32 rounding in the costs tracked by algorithms.
+const costTolerance = 1e-3
+
+// GroundTruth is the shortest path from start to end of a layout.
+type GroundTruth struct {
+	Reachable bool
+	Cost      float64
+}
+
+// Result is the outcome of checking one path.
+type Result struct {
+	// Err describes why the path is invalid, nil if it is valid.
+	Err     error
+	Reached bool
+	Cost    float64
+	// Optimal reports whether the path costs no more than the ground truth.
+	Optimal bool
+}
+
+// Passed reports whether the path is valid and reaches the end whenever the
+// end is reachable.
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Solve computes the ground truth for layout with a uniform-cost search over
+// the walls, which is a plain breadth-first search when all moves cost the
+// same. It does not share any code with the algorithms under test.
+func Solve(layout *maze.Layout, movement algorithms.Movement) GroundTruth {
+	width := layout.Width
+	dist := make([]float64, width*layout.Height)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+	}
+
+	start := int(layout.Start.Y)*width + int(layout.Start.X)
+	end := int(layout.End.Y)*width + int(layout.End.X)
+	dist[start] = 0
+	queue := &costQueue{{index: start}}
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(queued)
+		if current.cost > dist[current.index] {
+			continue
+		}
+		if current.index == end {
+			return GroundTruth{Reachable: true, Cost: current.cost}
+		}
+
+		x, y := current.index%width, current.index/width
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if !canMove(layout, movement, x, y, dx, dy) {
+					continue
+				}
+				next := (y+dy)*width + x + dx
+				cost := current.cost + moveCost(layout, x+dx, y+dy, dx, dy)
+				if cost < dist[next] {
+					dist[next] = cost
+					heap.Push(queue, queued{index: next, cost: cost})
+				}
+			}
+		}
+	}
+
+	return GroundTruth{}
+}
+
+// Check validates path, as reconstructed from the end node's PreviousNode
+// chain, against layout and truth.
+func Check(layout *maze.Layout, movement algorithms.Movement, truth GroundTruth, path []*maze.Node) Result {
+	result := Result{}
+	if len(path) == 0 {
+		result.Err = fmt.Errorf("empty path")
+		return result
+	}
+
+	first, last := path[0], path[len(path)-1]
+	result.Reached = first.X == layout.Start.X && first.Y == layout.Start.Y &&
+		last.X == layout.End.X && last.Y == layout.End.Y
+
+	for i, node := range path {
+		x, y := int(node.X), int(node.Y)
+		if x >= layout.Width || y >= layout.Height {
+			result.Err = fmt.Errorf("node %d at (%d,%d) is outside the maze", i, x, y)
+			return result
+		}
+		if layout.Walls[y][x] {
+			result.Err = fmt.Errorf("node %d at (%d,%d) is a wall", i, x, y)
+			return result
+		}
+		if i == 0 {
+			continue
+		}
+
+		dx, dy := x-int(path[i-1].X), y-int(path[i-1].Y)
+		if !canMove(layout, movement, int(path[i-1].X), int(path[i-1].Y), dx, dy) {
+			result.Err = fmt.Errorf("step %d from (%d,%d) to (%d,%d) is not a legal move",
+				i, path[i-1].X, path[i-1].Y, x, y)
+			return result
+		}
+		result.Cost += moveCost(layout, x, y, dx, dy)
+	}
+
+	switch {
+	case !result.Reached && truth.Reachable:
+		result.Err = fmt.Errorf("path from (%d,%d) to (%d,%d) does not connect start and end",
+			first.X, first.Y, last.X, last.Y)
+	case result.Reached && !truth.Reachable:
+		result.Err = fmt.Errorf("path reaches an end that is unreachable")
+	}
+	if truth.Reachable {
+		result.Optimal = result.Reached && result.Cost <= truth.Cost+costTolerance
+	} else {
+		// Reporting that there is no path is the best an algorithm can do.
+		result.Optimal = !result.Reached
+	}
+
+	return result
+}
+
+// canMove reports whether a move by (dx, dy) from (x, y) is legal.
+func canMove(layout *maze.Layout, movement algorithms.Movement, x, y, dx, dy int) bool {
+	if dx == 0 && dy == 0 || dx < -1 || dx > 1 || dy < -1 || dy > 1 {
+		return false
+	}
+	nx, ny := x+dx, y+dy
+	if nx < 0 || ny < 0 || nx >= layout.Width || ny >= layout.Height || layout.Walls[ny][nx] {
+		return false
+	}
+	if dx != 0 && dy != 0 {
+		if !movement.Diagonal() {
+			return false
+		}
+		if movement.NoCornerCutting && (layout.Walls[y][nx] || layout.Walls[ny][x]) {
+			return false
+		}
+	}
+	return true
+}
+
+// moveCost is the cost of entering (x, y) by a move of (dx, dy).
+func moveCost(layout *maze.Layout, x, y, dx, dy int) float64 {
+	cost := float64(layout.Cost(x, y))
+	if dx != 0 && dy != 0 {
+		cost *= math.Sqrt2
+	}
+	return cost
+}
+
+type queued struct {
+	index int
+	cost  float64
+}
+
+type costQueue []queued
+
+func (q costQueue) Len() int            { return len(q) }
+func (q costQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q costQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *costQueue) Push(x interface{}) { *q = append(*q, x.(queued)) }
+
+func (q *costQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
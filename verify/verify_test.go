@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+
+	"pathfinding_algorithms_test_runner/algorithms"
+	"pathfinding_algorithms_test_runner/maze"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		path    [][2]uint16
+		passed  bool
+		reached bool
+		optimal bool
+	}{
+		{
+			name:    "shortest path",
+			text:    "#####\n#S..#\n#.#.#\n#..E#\n#####\n",
+			path:    [][2]uint16{{1, 1}, {2, 1}, {3, 1}, {3, 2}, {3, 3}},
+			passed:  true,
+			reached: true,
+			optimal: true,
+		},
+		{
+			name:    "longer path",
+			text:    "#####\n#S..#\n#.#.#\n#..E#\n#.#.#\n#...#\n#####\n",
+			path:    [][2]uint16{{1, 1}, {1, 2}, {1, 3}, {1, 4}, {1, 5}, {2, 5}, {3, 5}, {3, 4}, {3, 3}},
+			passed:  true,
+			reached: true,
+		},
+		{
+			name:    "end not reached",
+			text:    "#####\n#S..#\n#.#.#\n#..E#\n#####\n",
+			path:    [][2]uint16{{3, 3}},
+			passed:  false,
+			reached: false,
+		},
+		{
+			name:    "unreachable end reported",
+			text:    "#####\n#S#E#\n#####\n",
+			path:    [][2]uint16{{3, 1}},
+			passed:  true,
+			optimal: true,
+		},
+		{
+			name:    "wall crossed",
+			text:    "#####\n#S#E#\n#####\n",
+			path:    [][2]uint16{{1, 1}, {2, 1}, {3, 1}},
+			reached: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			layout, err := maze.ReadText(strings.NewReader(test.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := make([]*maze.Node, len(test.path))
+			for i, cell := range test.path {
+				path[i] = &maze.Node{X: cell[0], Y: cell[1]}
+			}
+
+			truth := Solve(layout, algorithms.Movement{})
+			result := Check(layout, algorithms.Movement{}, truth, path)
+			if result.Passed() != test.passed || result.Reached != test.reached || result.Optimal != test.optimal {
+				t.Errorf("got passed %v, reached %v, optimal %v (err %v), want %v, %v, %v",
+					result.Passed(), result.Reached, result.Optimal, result.Err,
+					test.passed, test.reached, test.optimal)
+			}
+		})
+	}
+}